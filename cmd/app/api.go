@@ -8,8 +8,10 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/odev-swe/todoapp/docs"
 	"github.com/odev-swe/todoapp/internal/handlers"
+	"github.com/odev-swe/todoapp/internal/oidc"
 	"github.com/odev-swe/todoapp/internal/services"
 	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 )
@@ -45,11 +47,16 @@ func (app *application) Start() {
 
 	// middlewares
 	router.Use(app.LogMiddleware)
+	router.Use(app.ClientMiddleware)
 	router.Use(app.RateLimitMiddleware)
 	router.Use(middleware.Timeout(10 * time.Second))
 
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 
+	// public keys for verifying issued tokens
+	jwksHandler := handlers.NewJwksHandler(app.keys)
+	jwksHandler.RegisterRoute(router)
+
 	// prefix
 	router.Route("/api/v1", func(r chi.Router) {
 		// ping route
@@ -59,19 +66,91 @@ func (app *application) Start() {
 
 		// auth routes
 		r.Route("/auth", func(r chi.Router) {
-			authStore := store.NewAuthStore(app.db, app.config.JwtSecret)
-			authService := services.NewAuthService(authStore)
+			authStore := store.NewAuthStore(app.db, app.keys, app.denylist, app.hasher)
+			authService := services.NewAuthService(authStore, app.mailer, app.lockout, app.resetLimiter, app.magicLinkLimiter, app.audit, &app.config)
 			authHandler := handlers.NewAuthHandler(authService)
-			authHandler.RegisterRoute(r)
+			// personal access tokens carry no auth scope and are rejected here
+			authHandler.RegisterRoute(r, app.AuthMiddleware, app.ScopeMiddleware("auth"))
+
+			// openid connect login
+			r.Route("/oidc", func(r chi.Router) {
+				var providers []*oidc.Provider
+
+				for _, provider := range app.config.OidcProviders {
+					providers = append(providers, oidc.NewProvider(provider, nil))
+				}
+
+				oidcService := services.NewOidcService(authStore, store.NewOidcStore(app.redis), app.audit, providers)
+				oidcHandler := handlers.NewOidcHandler(oidcService)
+				oidcHandler.RegisterRoute(r)
+			})
+
+			// personal access tokens
+			r.Route("/tokens", func(r chi.Router) {
+				r.Use(app.AuthMiddleware)
+				r.Use(app.ScopeMiddleware("auth"))
+				tokensService := services.NewTokensService(app.tokens, app.audit)
+				tokensHandler := handlers.NewTokensHandler(tokensService)
+				tokensHandler.RegisterRoute(r)
+			})
+		})
+
+		// admin routes
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(app.AuthMiddleware)
+			r.Use(app.ScopeMiddleware("admin"))
+			r.Use(app.RequireRole(types.RoleAdmin))
+			authStore := store.NewAuthStore(app.db, app.keys, app.denylist, app.hasher)
+			adminService := services.NewAdminService(authStore, app.users, app.lockout, app.mailer, app.audit, &app.config)
+			adminHandler := handlers.NewAdminHandler(adminService)
+			adminHandler.RegisterRoute(r)
+		})
+
+		// workspaces, personal access tokens carry no scope for them
+		r.Route("/workspaces", func(r chi.Router) {
+			r.Use(app.AuthMiddleware)
+			r.Use(app.ScopeMiddleware("auth"))
+			workspacesService := services.NewWorkspacesService(store.NewWorkspacesStore(app.db))
+			workspacesHandler := handlers.NewWorkspacesHandler(workspacesService)
+			workspacesHandler.RegisterRoute(r)
 		})
 
 		// todos routes
 		r.Route("/todos", func(r chi.Router) {
 			r.Use(app.AuthMiddleware)
+			r.Use(app.VerifiedMiddleware)
+			r.Use(app.ScopeMiddleware("todos"))
+			r.Use(app.WorkspaceMiddleware)
 			todoStore := store.NewTodosStore(app.db, app.redis)
 			todoService := services.NewTodosService(todoStore)
 			todoHandler := handlers.NewTodosHandler(todoService)
 			todoHandler.RegisterRoute(r)
+			checklistService := services.NewChecklistService(store.NewChecklistStore(app.db, app.redis))
+			checklistHandler := handlers.NewChecklistHandler(checklistService)
+			r.Route("/{id}/checklist", checklistHandler.RegisterRoute)
+		})
+
+		// tags routes, tags belong to the workspace like its todos
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(app.AuthMiddleware)
+			r.Use(app.VerifiedMiddleware)
+			r.Use(app.ScopeMiddleware("todos"))
+			r.Use(app.WorkspaceMiddleware)
+			tagsService := services.NewTagsService(store.NewTagsStore(app.db, app.redis))
+			tagsHandler := handlers.NewTagsHandler(tagsService)
+			tagsHandler.RegisterRoute(r)
+		})
+
+		// projects routes, projects group the todos of a workspace
+		r.Route("/projects", func(r chi.Router) {
+			r.Use(app.AuthMiddleware)
+			r.Use(app.VerifiedMiddleware)
+			r.Use(app.ScopeMiddleware("todos"))
+			r.Use(app.WorkspaceMiddleware)
+			projectsStore := store.NewProjectsStore(app.db, app.redis)
+			projectsService := services.NewProjectsService(projectsStore, store.NewTodosStore(app.db, app.redis))
+			projectsHandler := handlers.NewProjectsHandler(projectsService)
+			projectsHandler.RegisterRoute(r)
 		})
 	})
 
@@ -1,19 +1,37 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odev-swe/todoapp/configs"
+	"github.com/odev-swe/todoapp/internal/denylist"
+	"github.com/odev-swe/todoapp/internal/lockout"
+	"github.com/odev-swe/todoapp/internal/mailer"
 	"github.com/odev-swe/todoapp/internal/ratelimiter"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/libs"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
 type application struct {
 	limiter ratelimiter.RateLimiter
-	config  configs.Config
-	db      *pgxpool.Pool
-	redis   *redis.Client
+	// reset and magic link requests are limited per email
+	resetLimiter     ratelimiter.RateLimiter
+	magicLinkLimiter ratelimiter.RateLimiter
+	denylist         denylist.Denylist
+	mailer           mailer.Mailer
+	lockout          lockout.Lockout
+	tokens           *store.TokensStore
+	users            *store.UsersStore
+	audit            *store.AuditStore
+	keys             *libs.KeySet
+	hasher           libs.PasswordHasher
+	config           configs.Config
+	db               *pgxpool.Pool
+	redis            *redis.Client
 }
 
 func main() {
@@ -34,12 +52,74 @@ func main() {
 	// application
 	app := &application{
 		limiter: ratelimiter.NewFixedWindowLimiter(envConfig.RateLimit, time.Duration(envConfig.RateLimitWindow)),
-		config:  *envConfig,
-		db:      db,
-		redis:   redis,
+		// emails are chosen by the client, their counters live in redis rather than in memory
+		resetLimiter:     ratelimiter.NewRedisLimiter(redis, "password-reset", envConfig.PasswordResetLimit, time.Duration(envConfig.PasswordResetWindow)*time.Second),
+		magicLinkLimiter: ratelimiter.NewRedisLimiter(redis, "magic-link", envConfig.MagicLinkLimit, time.Duration(envConfig.MagicLinkWindow)*time.Second),
+		// revocations only need to outlive the longest lived token
+		denylist: denylist.NewRedisDenylist(redis, libs.TokenTTL(libs.RefreshToken)),
+		mailer:   newMailer(envConfig),
+		lockout:  lockout.NewRedisLockout(redis),
+		tokens:   store.NewTokensStore(db),
+		users:    store.NewUsersStore(db),
+		audit:    store.NewAuditStore(db),
+		keys:     newKeySet(envConfig),
+		hasher: libs.NewArgon2idHasher(libs.Argon2idParams{
+			Memory:      uint32(envConfig.Argon2Memory),
+			Iterations:  uint32(envConfig.Argon2Iterations),
+			Parallelism: uint8(envConfig.Argon2Parallelism),
+			SaltLength:  libs.DefaultArgon2idParams.SaltLength,
+			KeyLength:   libs.DefaultArgon2idParams.KeyLength,
+		}),
+		config: *envConfig,
+		db:     db,
+		redis:  redis,
+	}
+
+	// grant the configured admins their role
+	err := app.users.PromoteAdmins(context.Background(), envConfig.AdminEmails)
+
+	if err != nil {
+		zap.L().Fatal("Error promoting admins", zap.Error(err))
 	}
 
 	// start http server
 	app.Start()
 
 }
+
+func newMailer(cfg *configs.Config) mailer.Mailer {
+	switch cfg.MailDriver {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.SmtpHost, cfg.SmtpPort, cfg.SmtpUser, cfg.SmtpPassword, cfg.MailFrom)
+	case "file":
+		return mailer.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	}
+
+	zap.L().Fatal("Unknown mail driver", zap.String("driver", cfg.MailDriver))
+
+	return nil
+}
+
+func newKeySet(cfg *configs.Config) *libs.KeySet {
+	var keys *libs.KeySet
+
+	if cfg.JwtPrivateKeyPath == "" {
+		if cfg.Env != "development" && cfg.JwtSecret == "secret" {
+			zap.L().Warn("JWT_SECRET is the default value, set JWT_PRIVATE_KEY_PATH or a strong JWT_SECRET")
+		}
+
+		keys = libs.NewHMACKeySet(cfg.JwtSecret)
+	} else {
+		var err error
+
+		keys, err = libs.LoadKeySet(cfg.JwtPrivateKeyPath, cfg.JwtPublicKeyPaths)
+
+		if err != nil {
+			zap.L().Fatal("Error loading JWT keys", zap.Error(err))
+		}
+	}
+
+	keys.SetIdentity(cfg.JwtIssuer, cfg.JwtAudience)
+
+	return keys
+}
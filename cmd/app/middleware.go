@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/odev-swe/todoapp/internal/types"
 	"github.com/odev-swe/todoapp/libs"
 	"go.uber.org/zap"
@@ -35,30 +38,187 @@ func (app *application) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		token := strings.Split(tokenHeader, "Bearer ")[1]
+		token, found := strings.CutPrefix(tokenHeader, "Bearer ")
 
-		if token == "" {
+		if !found || token == "" {
 			libs.Unauthorized(w, "Invalid Token")
 			return
 		}
 
-		claims, err := libs.ParseToken(token, app.config.JwtSecret)
+		// personal access tokens are looked up instead of verified
+		if strings.HasPrefix(token, types.PatPrefix) {
+			app.authenticatePat(w, r, next, token)
+			return
+		}
+
+		// refresh and verification tokens must not authenticate requests
+		claims, err := libs.ParseToken(token, app.keys, libs.AccessToken)
 
 		if err != nil {
 			libs.Unauthorized(w, "Invalid Token")
 			return
 		}
 
-		id := claims.Data.(map[string]interface{})["id"].(string)
+		id := claims.Subject
+
+		// check the token and its session against the denylist
+		revoked, err := app.denylist.IsRevoked(r.Context(), claims.ID)
+
+		if err != nil {
+			libs.InternalServerError(w, err.Error())
+			return
+		}
+
+		if !revoked && claims.SessionId != "" {
+			revoked, err = app.denylist.IsRevoked(r.Context(), claims.SessionId)
+
+			if err != nil {
+				libs.InternalServerError(w, err.Error())
+				return
+			}
+		}
+
+		cutoff, err := app.denylist.RevokedBefore(r.Context(), id)
+
+		if err != nil {
+			libs.InternalServerError(w, err.Error())
+			return
+		}
+
+		if !cutoff.IsZero() && (claims.IssuedAt == nil || claims.IssuedAt.Before(cutoff)) {
+			revoked = true
+		}
+
+		if revoked {
+			libs.Unauthorized(w, "Token has been revoked")
+			return
+		}
 
 		// wrap in context
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, types.UserIdKey("user-id"), id)
+		ctx = context.WithValue(ctx, types.TokenIdKey("token-id"), claims.ID)
+		ctx = context.WithValue(ctx, types.TokenExpiresAtKey("token-expires-at"), claims.ExpiresAt.Time)
+		ctx = context.WithValue(ctx, types.SessionIdKey("session-id"), claims.SessionId)
+		ctx = context.WithValue(ctx, types.UserRolesKey("user-roles"), claims.Roles)
+		ctx = context.WithValue(ctx, types.EmailVerifiedKey("email-verified"), claims.EmailVerified)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (app *application) authenticatePat(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	pat, user, err := app.tokens.Authenticate(r.Context(), token)
+
+	if errors.Is(err, types.ErrInvalidToken) {
+		libs.Unauthorized(w, "Invalid Token")
+		return
+	}
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	// a non nil scope list marks the request as authenticated by a personal access token
+	scopes := pat.Scopes
+
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	// wrap in context
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, types.UserIdKey("user-id"), pat.UserId.String())
+	ctx = context.WithValue(ctx, types.EmailVerifiedKey("email-verified"), user.VerifiedAt != nil)
+	ctx = context.WithValue(ctx, types.TokenScopesKey("token-scopes"), scopes)
+	ctx = context.WithValue(ctx, types.UserRolesKey("user-roles"), []string{user.Role})
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// ScopeMiddleware limits personal access tokens to the scopes they were granted on
+// the given resource, safe methods need the read scope and anything else the write
+// scope. Requests authenticated with an access token are not limited.
+// It must run after AuthMiddleware.
+func (app *application) ScopeMiddleware(resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, isPat := r.Context().Value(types.TokenScopesKey("token-scopes")).([]string)
+
+			if !isPat {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			required := resource + ":write"
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				required = resource + ":read"
+			}
+
+			if !types.HasScope(scopes, required) {
+				libs.Forbidden(w, "Token is missing the "+required+" scope")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// VerifiedMiddleware rejects users with an unverified email when the config requires it,
+// it must run after AuthMiddleware
+func (app *application) VerifiedMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verified, _ := r.Context().Value(types.EmailVerifiedKey("email-verified")).(bool)
+
+		if app.config.RequireVerifiedEmail && !verified {
+			libs.Forbidden(w, "Email address is not verified")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientMiddleware stores the client ip and user agent in the request context
+func (app *application) ClientMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, types.ClientIpKey("client-ip"), ip)
+		ctx = context.WithValue(ctx, types.UserAgentKey("user-agent"), r.UserAgent())
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// RequireRole only lets through users holding one of the given roles,
+// it must run after AuthMiddleware
+func (app *application) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			granted, _ := r.Context().Value(types.UserRolesKey("user-roles")).([]string)
+
+			for _, role := range granted {
+				if slices.Contains(roles, role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			libs.Forbidden(w, "Missing the required role")
+		})
+	}
+}
+
 func (app *application) RateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ip, _, err := net.SplitHostPort(r.RemoteAddr)
@@ -76,3 +236,27 @@ func (app *application) RateLimitMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// WorkspaceMiddleware stores the workspace selected with the X-Workspace-ID
+// header in the request context, membership is checked by the store
+func (app *application) WorkspaceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(types.WorkspaceHeader)
+
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := uuid.Parse(header)
+
+		if err != nil {
+			libs.BadRequest(w, types.ErrInvalidWorkspaceHeader.Error())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), types.WorkspaceIdKey("workspace-id"), id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
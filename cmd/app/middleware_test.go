@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/denylist"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserId = "3162d3f0-5532-402d-ab85-28946a279cac"
+
+func testApplication() *application {
+	return &application{
+		keys:     libs.NewHMACKeySet("secret"),
+		denylist: denylist.NewMemoryDenylist(time.Hour),
+	}
+}
+
+func accessToken(t *testing.T, app *application, role string) string {
+	token, err := libs.GenerateToken(libs.CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: testUserId},
+		Type:             libs.AccessToken,
+		Roles:            []string{role},
+	}, app.keys)
+	require.NoError(t, err)
+
+	return token
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestRequireRole(t *testing.T) {
+	app := testApplication()
+
+	tests := []struct {
+		name           string
+		roles          []string
+		expectedStatus int
+	}{
+		{"Admin", []string{types.RoleAdmin}, http.StatusOK},
+		{"User", []string{types.RoleUser}, http.StatusForbidden},
+		{"No Roles", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/admin/users", nil)
+			req = req.WithContext(context.WithValue(req.Context(), types.UserRolesKey("user-roles"), tt.roles))
+			rr := httptest.NewRecorder()
+
+			app.RequireRole(types.RoleAdmin)(okHandler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAdminRoutesNeedAdminToken(t *testing.T) {
+	app := testApplication()
+	handler := app.AuthMiddleware(app.RequireRole(types.RoleAdmin)(okHandler))
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{"Admin", accessToken(t, app, types.RoleAdmin), http.StatusOK},
+		{"Non Admin", accessToken(t, app, types.RoleUser), http.StatusForbidden},
+		{"Missing Token", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/admin/users", nil)
+
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestDisabledUserTokenIsRejected(t *testing.T) {
+	app := testApplication()
+	handler := app.AuthMiddleware(okHandler)
+	token := accessToken(t, app, types.RoleUser)
+
+	request := func() int {
+		req, _ := http.NewRequest(http.MethodGet, "/todos", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request())
+
+	// what disabling a user or forcing a password reset does to their access tokens
+	require.NoError(t, app.denylist.RevokeUser(context.Background(), testUserId, time.Now()))
+
+	assert.Equal(t, http.StatusUnauthorized, request())
+}
+
+func TestWorkspaceMiddleware(t *testing.T) {
+	app := testApplication()
+
+	workspaceId := uuid.New()
+
+	tests := []struct {
+		name              string
+		header            string
+		expectedStatus    int
+		expectedWorkspace *uuid.UUID
+	}{
+		{"Selected Workspace", workspaceId.String(), http.StatusOK, &workspaceId},
+		{"Personal Workspace", "", http.StatusOK, nil},
+		{"Invalid Header", "team", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var selected *uuid.UUID
+
+			handler := app.WorkspaceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if id, ok := r.Context().Value(types.WorkspaceIdKey("workspace-id")).(uuid.UUID); ok {
+					selected = &id
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req, _ := http.NewRequest(http.MethodGet, "/todos", nil)
+
+			if tt.header != "" {
+				req.Header.Set(types.WorkspaceHeader, tt.header)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedWorkspace, selected)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package configs
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
@@ -18,9 +19,55 @@ type Config struct {
 	RateLimitWindow int
 	Port            string
 	JwtSecret       string
-	Env             string
-	RedisHost       string
-	RedisPort       string
+	// asymmetric signing key, the shared secret is used when empty
+	JwtPrivateKeyPath string
+	// previous public keys still accepted while rotating
+	JwtPublicKeyPaths []string
+	// iss and aud claims of issued tokens
+	JwtIssuer   string
+	JwtAudience string
+	Env         string
+	RedisHost   string
+	RedisPort   string
+	AppUrl      string
+	// reject unverified users on the todos routes
+	RequireVerifiedEmail bool
+	MailDriver           string
+	MailFrom             string
+	MailDir              string
+	SmtpHost             string
+	SmtpPort             string
+	SmtpUser             string
+	SmtpPassword         string
+	// reset requests allowed per email within the window, in seconds
+	PasswordResetLimit  int
+	PasswordResetWindow int
+	// lifetime of a reset token in seconds
+	PasswordResetTTL int
+	// sign-in link requests allowed per email within the window, in seconds
+	MagicLinkLimit  int
+	MagicLinkWindow int
+	// lifetime of a sign-in link in seconds
+	MagicLinkTTL  int
+	OidcProviders []OidcProvider
+	// failed logins allowed per account and per ip within the window, in seconds
+	LoginMaxFailures   int
+	LoginIpMaxFailures int
+	LoginFailureWindow int
+	// first lockout in seconds, doubled on every further failure up to the max
+	LoginLockoutBase int
+	LoginLockoutMax  int
+	// argon2id cost, memory in KiB
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	// password policy for new passwords
+	PasswordMinLength    int
+	PasswordMaxLength    int
+	PasswordMinClasses   int
+	PasswordRejectCommon bool
+	// users granted the admin role at startup
+	AdminEmails []string
 }
 
 func NewEnv() *Config {
@@ -33,19 +80,56 @@ func NewEnv() *Config {
 	}
 
 	zap.L().Info("Loaded .env file")
+
+	port := getEnv("PORT", "3000")
+
 	return &Config{
-		DbUser:          getEnv("POSTGRES_USER", "postgres"),
-		DbPassword:      getEnv("POSTGRES_PASSWORD", "postgres"),
-		DbName:          getEnv("POSTGRES_DB", "postgres"),
-		DbHost:          getEnv("POSTGRES_HOST", "postgres"),
-		DbPort:          getEnv("POSTGRES_PORT", "5432"),
-		RateLimit:       getEnvInt("RATE_LIMITER_MAX_REQUESTS", 10),
-		RateLimitWindow: getEnvInt("RATE_LIMITER_WINDOW", 10),
-		Port:            getEnv("PORT", "3000"),
-		Env:             getEnv("ENV", "development"),
-		JwtSecret:       getEnv("JWT_SECRET", "secret"),
-		RedisHost:       getEnv("REDIS_HOST", "redis"),
-		RedisPort:       getEnv("REDIS_PORT", "6379"),
+		DbUser:            getEnv("POSTGRES_USER", "postgres"),
+		DbPassword:        getEnv("POSTGRES_PASSWORD", "postgres"),
+		DbName:            getEnv("POSTGRES_DB", "postgres"),
+		DbHost:            getEnv("POSTGRES_HOST", "postgres"),
+		DbPort:            getEnv("POSTGRES_PORT", "5432"),
+		RateLimit:         getEnvInt("RATE_LIMITER_MAX_REQUESTS", 10),
+		RateLimitWindow:   getEnvInt("RATE_LIMITER_WINDOW", 10),
+		Port:              port,
+		Env:               getEnv("ENV", "development"),
+		JwtSecret:         getEnv("JWT_SECRET", "secret"),
+		JwtPrivateKeyPath: getEnv("JWT_PRIVATE_KEY_PATH", ""),
+		JwtPublicKeyPaths: getEnvList("JWT_PUBLIC_KEY_PATHS"),
+		JwtIssuer:         getEnv("JWT_ISSUER", "todoapp"),
+		JwtAudience:       getEnv("JWT_AUDIENCE", "todoapp-api"),
+		RedisHost:         getEnv("REDIS_HOST", "redis"),
+		RedisPort:         getEnv("REDIS_PORT", "6379"),
+		AppUrl:            getEnv("APP_URL", "http://localhost:3000"),
+		// unverified users keep access unless explicitly required
+		RequireVerifiedEmail: getEnvBool("REQUIRE_VERIFIED_EMAIL", false),
+		MailDriver:           getEnv("MAIL_DRIVER", "file"),
+		MailFrom:             getEnv("MAIL_FROM", "no-reply@todoapp.local"),
+		MailDir:              getEnv("MAIL_DIR", "tmp/mail"),
+		SmtpHost:             getEnv("SMTP_HOST", "localhost"),
+		SmtpPort:             getEnv("SMTP_PORT", "587"),
+		SmtpUser:             getEnv("SMTP_USER", ""),
+		SmtpPassword:         getEnv("SMTP_PASSWORD", ""),
+		PasswordResetLimit:   getEnvInt("PASSWORD_RESET_MAX_REQUESTS", 3),
+		PasswordResetWindow:  getEnvInt("PASSWORD_RESET_WINDOW", 3600),
+		PasswordResetTTL:     getEnvInt("PASSWORD_RESET_TTL", 1800),
+		MagicLinkLimit:       getEnvInt("MAGIC_LINK_MAX_REQUESTS", 3),
+		MagicLinkWindow:      getEnvInt("MAGIC_LINK_WINDOW", 3600),
+		MagicLinkTTL:         getEnvInt("MAGIC_LINK_TTL", 900),
+		OidcProviders:        getOidcProviders(port),
+		LoginMaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginIpMaxFailures:   getEnvInt("LOGIN_IP_MAX_FAILURES", 20),
+		LoginFailureWindow:   getEnvInt("LOGIN_FAILURE_WINDOW", 900),
+		LoginLockoutBase:     getEnvInt("LOGIN_LOCKOUT_BASE", 30),
+		LoginLockoutMax:      getEnvInt("LOGIN_LOCKOUT_MAX", 3600),
+		Argon2Memory:         getEnvInt("ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:     getEnvInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:    getEnvInt("ARGON2_PARALLELISM", 4),
+		PasswordMinLength:    getEnvInt("PASSWORD_MIN_LENGTH", 10),
+		PasswordMaxLength:    getEnvInt("PASSWORD_MAX_LENGTH", 128),
+		PasswordMinClasses:   getEnvInt("PASSWORD_MIN_CLASSES", 1),
+		PasswordRejectCommon: getEnvBool("PASSWORD_REJECT_COMMON", true),
+		AdminEmails:          getEnvList("ADMIN_EMAILS"),
 	}
 }
 
@@ -67,3 +151,33 @@ func getEnvInt(key string, defaultValue int) int {
 
 	return val
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	val, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return defaultValue
+	}
+
+	return val
+}
+
+// getEnvList reads a comma separated list, dropping empty items
+func getEnvList(key string) []string {
+	var list []string
+
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		item = strings.TrimSpace(item)
+
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
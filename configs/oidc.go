@@ -0,0 +1,42 @@
+package configs
+
+import (
+	"fmt"
+	"strings"
+)
+
+type OidcProvider struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+}
+
+// getOidcProviders reads the providers listed in OIDC_PROVIDERS, each one is
+// configured through OIDC_<NAME>_* variables
+func getOidcProviders(port string) []OidcProvider {
+	var providers []OidcProvider
+
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		providers = append(providers, OidcProvider{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientId:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectUrl:  getEnv(prefix+"REDIRECT_URL", fmt.Sprintf("http://localhost:%s/api/v1/auth/oidc/%s/callback", port, name)),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+
+	return providers
+}
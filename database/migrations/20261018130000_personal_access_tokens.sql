@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- only the sha256 of a token is stored, token_prefix helps users recognise their tokens
+-- an empty scopes array grants every scope
+CREATE TABLE personal_access_tokens (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  token_prefix VARCHAR(16) NOT NULL,
+  scopes TEXT[] NOT NULL DEFAULT '{}',
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX personal_access_tokens_user_id_idx ON personal_access_tokens(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE personal_access_tokens;
+-- +goose StatementEnd
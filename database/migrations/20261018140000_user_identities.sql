@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- accounts created through an identity provider have no password
+ALTER TABLE users ALTER COLUMN password DROP NOT NULL;
+
+-- external identities linked to a user, subject is the provider's stable user id
+CREATE TABLE user_identities (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider VARCHAR(64) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (provider, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_identities;
+
+-- passwordless accounts can not satisfy the constraint anymore
+DELETE FROM users WHERE password IS NULL;
+ALTER TABLE users ALTER COLUMN password SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one session per login, the id is shared with the refresh token family
+CREATE TABLE sessions (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  device VARCHAR(255) NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  revoked_at TIMESTAMP
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sessions;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+-- disabled users cannot log in and lose every token they hold
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN disabled_at;
+ALTER TABLE users DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN display_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT 'en';
+
+-- deleting a user removes their todos
+ALTER TABLE todos DROP CONSTRAINT todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos DROP CONSTRAINT todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE users DROP COLUMN locale;
+ALTER TABLE users DROP COLUMN timezone;
+ALTER TABLE users DROP COLUMN display_name;
+-- +goose StatementEnd
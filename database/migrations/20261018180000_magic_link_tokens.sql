@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- single use sign-in links, keyed by email since the account may not exist yet.
+-- only the sha256 of a token is stored
+CREATE TABLE magic_link_tokens (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  email VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX magic_link_tokens_email_idx ON magic_link_tokens(email);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE magic_link_tokens;
+-- +goose StatementEnd
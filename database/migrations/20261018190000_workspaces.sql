@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+-- personal_user_id is set on the workspace every user gets on sign up
+CREATE TABLE workspaces (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  personal_user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON workspaces
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+CREATE TABLE workspace_members (
+  workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(16) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX workspace_members_user_id_idx ON workspace_members(user_id);
+
+-- every existing user keeps their todos in a personal workspace
+INSERT INTO workspaces (name, personal_user_id) SELECT 'Personal', id FROM users;
+INSERT INTO workspace_members (workspace_id, user_id, role) SELECT id, personal_user_id, 'owner' FROM workspaces;
+
+ALTER TABLE todos ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE todos t SET workspace_id = w.id FROM workspaces w WHERE w.personal_user_id = t.user_id;
+
+-- todos without an owner were never reachable
+DELETE FROM todos WHERE workspace_id IS NULL;
+ALTER TABLE todos ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX todos_workspace_id_idx ON todos(workspace_id);
+
+-- user_id is now the author, shared todos outlive the account that wrote them
+ALTER TABLE todos DROP CONSTRAINT todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- todos of shared workspaces go back to their author, orphans are dropped
+DELETE FROM todos WHERE user_id IS NULL;
+
+ALTER TABLE todos DROP CONSTRAINT todos_user_id_fkey;
+ALTER TABLE todos ADD CONSTRAINT todos_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE todos DROP COLUMN workspace_id;
+
+DROP TABLE workspace_members;
+DROP TABLE workspaces;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- security events, each row hashes the previous one so tampering is detectable.
+-- actor_id has no foreign key, events outlive the users they mention
+CREATE TABLE audit_events (
+  id BIGSERIAL PRIMARY KEY,
+  event_type VARCHAR(64) NOT NULL,
+  actor_id UUID,
+  ip VARCHAR(64) NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  metadata JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMP NOT NULL,
+  prev_hash VARCHAR(64) NOT NULL,
+  hash VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX audit_events_event_type_idx ON audit_events(event_type);
+CREATE INDEX audit_events_actor_id_idx ON audit_events(actor_id);
+CREATE INDEX audit_events_created_at_idx ON audit_events(created_at);
+
+-- append only, rows can not be changed or removed through normal statements
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT
+EXECUTE FUNCTION audit_events_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_events;
+
+DROP FUNCTION audit_events_append_only;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- title substring search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- the list is always scoped to a workspace, the default order is the newest
+-- first. created_at is nullable, the nulls come last as in the list order so
+-- the index also serves the keyset pages and, scanned backward, reverse pages
+CREATE INDEX todos_workspace_id_created_at_idx ON todos(workspace_id, created_at DESC NULLS LAST, id);
+CREATE INDEX todos_workspace_id_due_date_idx ON todos(workspace_id, due_date);
+CREATE INDEX todos_workspace_id_completed_idx ON todos(workspace_id, completed, due_date);
+CREATE INDEX todos_title_trgm_idx ON todos USING GIN (title gin_trgm_ops);
+
+-- covered by the workspace and created_at index
+DROP INDEX todos_workspace_id_idx;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX todos_workspace_id_idx ON todos(workspace_id);
+
+DROP INDEX todos_title_trgm_idx;
+DROP INDEX todos_workspace_id_completed_idx;
+DROP INDEX todos_workspace_id_due_date_idx;
+DROP INDEX todos_workspace_id_created_at_idx;
+-- +goose StatementEnd
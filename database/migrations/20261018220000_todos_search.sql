@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the simple configuration does not stem, so a prefix of a word always matches it
+ALTER TABLE todos ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+  setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+CREATE INDEX todos_search_vector_idx ON todos USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX todos_search_vector_idx;
+
+ALTER TABLE todos DROP COLUMN search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- tags are shared by the members of a workspace like its todos
+CREATE TABLE tags (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  color VARCHAR(7) NOT NULL DEFAULT '#808080',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- names are unique regardless of case
+CREATE UNIQUE INDEX tags_workspace_id_name_idx ON tags(workspace_id, lower(name));
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON tags
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- deleting a tag or a todo removes the link
+CREATE TABLE todo_tags (
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX todo_tags_tag_id_idx ON todo_tags(tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE todo_tags;
+
+DROP TABLE tags;
+-- +goose StatementEnd
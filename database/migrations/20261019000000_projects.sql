@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- projects group the todos of a workspace, todos without one are in the inbox
+CREATE TABLE projects (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  color VARCHAR(7) NOT NULL DEFAULT '#808080',
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  position INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  -- referenced with the workspace so a todo can not join a project of another workspace
+  UNIQUE (id, workspace_id)
+);
+
+CREATE INDEX projects_workspace_id_position_idx ON projects(workspace_id, position);
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON projects
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- the todos of a deleted project are moved or deleted by the application first
+ALTER TABLE todos ADD COLUMN project_id UUID;
+ALTER TABLE todos ADD CONSTRAINT todos_project_fkey FOREIGN KEY (project_id, workspace_id) REFERENCES projects(id, workspace_id);
+
+CREATE INDEX todos_project_id_idx ON todos(project_id, completed);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos DROP COLUMN project_id;
+
+DROP TABLE projects;
+-- +goose StatementEnd
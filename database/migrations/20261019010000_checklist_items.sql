@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- items break a todo down, they are deleted with it
+CREATE TABLE checklist_items (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  title VARCHAR(255) NOT NULL,
+  done BOOLEAN NOT NULL DEFAULT FALSE,
+  position INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX checklist_items_todo_id_position_idx ON checklist_items(todo_id, position);
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON checklist_items
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- the todo is completed when every item is done, and reopened when one is not
+ALTER TABLE todos ADD COLUMN auto_complete BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todos DROP COLUMN auto_complete;
+
+DROP TABLE checklist_items;
+-- +goose StatementEnd
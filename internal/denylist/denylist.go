@@ -0,0 +1,17 @@
+package denylist
+
+import (
+	"context"
+	"time"
+)
+
+// Denylist keeps track of tokens revoked before they expire
+type Denylist interface {
+	// Revoke denies a single token id until the token expires
+	Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenId string) (bool, error)
+	// RevokeUser denies every token issued to the user before the given time
+	RevokeUser(ctx context.Context, userId string, before time.Time) error
+	// RevokedBefore returns the user's revocation cutoff, zero if there is none
+	RevokedBefore(ctx context.Context, userId string) (time.Time, error)
+}
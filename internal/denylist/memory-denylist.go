@@ -0,0 +1,97 @@
+package denylist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type userCutoff struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+type MemoryDenylist struct {
+	sync.RWMutex
+	tokens    map[string]time.Time
+	users     map[string]userCutoff
+	retention time.Duration
+}
+
+// NewMemoryDenylist keeps revocations in process memory. User cutoffs are
+// kept for retention, which should cover the longest lived token.
+func NewMemoryDenylist(retention time.Duration) *MemoryDenylist {
+	return &MemoryDenylist{
+		tokens:    make(map[string]time.Time),
+		users:     make(map[string]userCutoff),
+		retention: retention,
+	}
+}
+
+func (d *MemoryDenylist) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	d.Lock()
+	defer d.Unlock()
+
+	d.prune()
+	d.tokens[tokenId] = expiresAt
+
+	return nil
+}
+
+func (d *MemoryDenylist) IsRevoked(ctx context.Context, tokenId string) (bool, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	expiresAt, exist := d.tokens[tokenId]
+
+	return exist && time.Now().Before(expiresAt), nil
+}
+
+func (d *MemoryDenylist) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	d.Lock()
+	defer d.Unlock()
+
+	d.prune()
+
+	// never move an existing cutoff backwards
+	if current, exist := d.users[userId]; exist && current.before.After(before) {
+		return nil
+	}
+
+	d.users[userId] = userCutoff{
+		before:    before,
+		expiresAt: time.Now().Add(d.retention),
+	}
+
+	return nil
+}
+
+func (d *MemoryDenylist) RevokedBefore(ctx context.Context, userId string) (time.Time, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	cutoff, exist := d.users[userId]
+
+	if !exist || time.Now().After(cutoff.expiresAt) {
+		return time.Time{}, nil
+	}
+
+	return cutoff.before, nil
+}
+
+// prune drops entries that can no longer match a live token, caller must hold the lock
+func (d *MemoryDenylist) prune() {
+	now := time.Now()
+
+	for id, expiresAt := range d.tokens {
+		if now.After(expiresAt) {
+			delete(d.tokens, id)
+		}
+	}
+
+	for id, cutoff := range d.users {
+		if now.After(cutoff.expiresAt) {
+			delete(d.users, id)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package denylist
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RedisDenylist shares revocations between instances through redis.
+// Every write is mirrored in memory so revocations keep working on
+// this instance while redis is unreachable.
+type RedisDenylist struct {
+	client    *redis.Client
+	fallback  *MemoryDenylist
+	retention time.Duration
+}
+
+func NewRedisDenylist(client *redis.Client, retention time.Duration) *RedisDenylist {
+	return &RedisDenylist{
+		client:    client,
+		fallback:  NewMemoryDenylist(retention),
+		retention: retention,
+	}
+}
+
+func tokenKey(tokenId string) string {
+	return "denylist:token:" + tokenId
+}
+
+func userKey(userId string) string {
+	return "denylist:user:" + userId
+}
+
+func (d *RedisDenylist) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	d.fallback.Revoke(ctx, tokenId, expiresAt)
+
+	ttl := time.Until(expiresAt)
+
+	if ttl <= 0 {
+		return nil
+	}
+
+	err := d.client.Set(ctx, tokenKey(tokenId), 1, ttl).Err()
+
+	if err != nil {
+		zap.L().Warn("denylist: redis unavailable, token revoked in memory only", zap.Error(err))
+	}
+
+	return nil
+}
+
+func (d *RedisDenylist) IsRevoked(ctx context.Context, tokenId string) (bool, error) {
+	count, err := d.client.Exists(ctx, tokenKey(tokenId)).Result()
+
+	if err != nil {
+		zap.L().Warn("denylist: redis unavailable, using in-memory fallback", zap.Error(err))
+		return d.fallback.IsRevoked(ctx, tokenId)
+	}
+
+	if count > 0 {
+		return true, nil
+	}
+
+	return d.fallback.IsRevoked(ctx, tokenId)
+}
+
+func (d *RedisDenylist) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	d.fallback.RevokeUser(ctx, userId, before)
+
+	current, err := d.RevokedBefore(ctx, userId)
+
+	if err != nil {
+		return err
+	}
+
+	// never move an existing cutoff backwards
+	if current.After(before) {
+		return nil
+	}
+
+	err = d.client.Set(ctx, userKey(userId), before.UnixNano(), d.retention).Err()
+
+	if err != nil {
+		zap.L().Warn("denylist: redis unavailable, user revoked in memory only", zap.Error(err))
+	}
+
+	return nil
+}
+
+func (d *RedisDenylist) RevokedBefore(ctx context.Context, userId string) (time.Time, error) {
+	local, _ := d.fallback.RevokedBefore(ctx, userId)
+
+	value, err := d.client.Get(ctx, userKey(userId)).Result()
+
+	if err == redis.Nil {
+		return local, nil
+	}
+
+	if err != nil {
+		zap.L().Warn("denylist: redis unavailable, using in-memory fallback", zap.Error(err))
+		return local, nil
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return local, err
+	}
+
+	remote := time.Unix(0, nanos)
+
+	if local.After(remote) {
+		return local, nil
+	}
+
+	return remote, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// Auth godoc
+//
+//	@Summary		Get the current user
+//	@Description	get the profile of the current user
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/me [get]
+func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authService.GetMe(r.Context())
+
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User retrieved successfully", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Update the current user
+//	@Description	change the display name, timezone or locale of the current user, omitted fields are kept
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.ProfilePatchRequestBody	true	"Profile fields to change"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/me [patch]
+func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.ProfilePatchRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.authService.UpdateMe(r.Context(), req)
+
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User updated successfully", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Change password
+//	@Description	change the password of the current user, every other session is logged out and personal access tokens are deleted. Accounts without a password set their first one with a session started through a magic link or an identity provider in the last 10 minutes
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.ChangePasswordRequestBody	true	"Current and new password"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/password/change [post]
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.ChangePasswordRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.NewPassword == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.ChangePassword(r.Context(), req)
+
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Password changed successfully", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Delete the current user
+//	@Description	delete the current user with their personal todos and the shared workspaces nobody else is in, accounts without a password confirm with a session started through a magic link or an identity provider in the last 10 minutes. Fails while the user is the last owner of a shared workspace
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.DeleteAccountRequestBody	true	"Current password"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		409	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/me [delete]
+func (h *AuthHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.DeleteAccountRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.DeleteMe(r.Context(), req)
+
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User deleted successfully", nil)
+}
+
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrInvalidDisplayName), errors.Is(err, types.ErrInvalidTimezone), errors.Is(err, types.ErrInvalidLocale),
+		errors.Is(err, libs.ErrWeakPassword):
+		libs.BadRequest(w, err.Error())
+	case errors.Is(err, types.ErrInvalidPassword), errors.Is(err, types.ErrReauthRequired):
+		libs.Forbidden(w, err.Error())
+	case errors.Is(err, types.ErrUserNotFound):
+		libs.Unauthorized(w, err.Error())
+	case errors.Is(err, types.ErrLastOwner):
+		libs.WriteJSON(w, false, http.StatusConflict, err.Error(), nil)
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type AdminHandler struct {
+	service types.AdminServices
+}
+
+func NewAdminHandler(service types.AdminServices) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (h *AdminHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/users", h.GetUsers)
+	r.Post("/users/{id}/disable", h.DisableUser)
+	r.Post("/users/{id}/enable", h.EnableUser)
+	r.Post("/users/{id}/password-reset", h.ForcePasswordReset)
+	r.Put("/users/{id}/role", h.SetRole)
+	r.Post("/unlock", h.Unlock)
+	r.Get("/audit-events", h.GetAuditEvents)
+	r.Get("/audit-events/verify", h.VerifyAuditLog)
+}
+
+// Admin godoc
+//
+//	@Summary		Get users
+//	@Description	list every user with their role and status
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit	query	int	false	"Limit"		default(50)
+//	@Param			offset	query	int	false	"Offset"	default(0)
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/users [get]
+func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	query := types.UsersQuery{Limit: 50}
+
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		query.Limit = l
+	}
+
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		query.Offset = o
+	}
+
+	res, err := h.service.GetUsers(r.Context(), query)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Users retrieved successfully", res)
+}
+
+// Admin godoc
+//
+//	@Summary		Disable a user
+//	@Description	block the user from logging in, every token and session they hold is revoked immediately and their personal access tokens are deleted
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id	path	string	true	"User id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/users/{id}/disable [post]
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.userAction(w, r, h.service.DisableUser, "User disabled successfully")
+}
+
+// Admin godoc
+//
+//	@Summary		Enable a user
+//	@Description	allow a disabled user to log in again
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id	path	string	true	"User id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/users/{id}/enable [post]
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.userAction(w, r, h.service.EnableUser, "User enabled successfully")
+}
+
+// Admin godoc
+//
+//	@Summary		Force a password reset
+//	@Description	clear the password of the user, revoke their tokens and sessions, delete their personal access tokens and email them a reset link
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id	path	string	true	"User id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/users/{id}/password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	h.userAction(w, r, h.service.ForcePasswordReset, "Password reset successfully")
+}
+
+// Admin godoc
+//
+//	@Summary		Change the role of a user
+//	@Description	grant or remove the admin role, the access tokens of the user are revoked so the next refresh carries the new role
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string					true	"User id"
+//	@Param			body	body	types.RoleRequestBody	true	"New role, user or admin"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/users/{id}/role [put]
+func (h *AdminHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.RoleRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.userAction(w, r, func(ctx context.Context, id uuid.UUID) error {
+		return h.service.SetRole(ctx, id, req)
+	}, "User role changed successfully")
+}
+
+// Admin godoc
+//
+//	@Summary		Unlock a login
+//	@Description	clear the failed login attempts and lockout of an account, an ip or both
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.UnlockRequestBody	true	"Email and/or ip to unlock"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/unlock [post]
+func (h *AdminHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.UnlockRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || (req.Email == "" && req.Ip == "") {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.service.Unlock(r.Context(), req)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Login unlocked successfully", nil)
+}
+
+// Admin godoc
+//
+//	@Summary		Get audit events
+//	@Description	list security events newest first, filtered by type, actor and time range
+//	@Tags			admin
+//	@Produce		json
+//	@Param			type		query	string	false	"Event type"
+//	@Param			actor_id	query	string	false	"Actor user id"
+//	@Param			from		query	string	false	"Earliest time, RFC 3339"
+//	@Param			to			query	string	false	"Latest time (exclusive), RFC 3339"
+//	@Param			limit		query	int		false	"Limit"		default(50)
+//	@Param			offset		query	int		false	"Offset"	default(0)
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/audit-events [get]
+func (h *AdminHandler) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := types.AuditQuery{Type: params.Get("type"), Limit: 50}
+
+	if l, err := strconv.Atoi(params.Get("limit")); err == nil && l > 0 {
+		query.Limit = l
+	}
+
+	if o, err := strconv.Atoi(params.Get("offset")); err == nil && o > 0 {
+		query.Offset = o
+	}
+
+	if v := params.Get("actor_id"); v != "" {
+		id, err := uuid.Parse(v)
+
+		if err != nil {
+			libs.BadRequest(w, "Invalid actor id")
+			return
+		}
+
+		query.ActorId = &id
+	}
+
+	for name, bound := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		v := params.Get(name)
+
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+
+		if err != nil {
+			libs.BadRequest(w, "Invalid "+name+" time")
+			return
+		}
+
+		*bound = &t
+	}
+
+	res, err := h.service.GetAuditEvents(r.Context(), query)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Audit events retrieved successfully", res)
+}
+
+// Admin godoc
+//
+//	@Summary		Verify the audit log
+//	@Description	recompute the hash chain of the audit log, an invalid result names the first event that was altered or removed after it
+//	@Tags			admin
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/admin/audit-events/verify [get]
+func (h *AdminHandler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.VerifyAuditLog(r.Context())
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Audit log verified", res)
+}
+
+// userAction runs an action on the user in the id path parameter
+func (h *AdminHandler) userAction(w http.ResponseWriter, r *http.Request, action func(context.Context, uuid.UUID) error, msg string) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid user id")
+		return
+	}
+
+	err = action(r.Context(), id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrUserNotFound):
+			libs.NotFound(w, err.Error())
+		case errors.Is(err, types.ErrInvalidRole):
+			libs.BadRequest(w, err.Error())
+		default:
+			libs.InternalServerError(w, err.Error())
+		}
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, msg, nil)
+}
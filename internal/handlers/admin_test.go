@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAdminService is a mock implementation of the AdminService
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) Unlock(ctx context.Context, req types.UnlockRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) GetUsers(ctx context.Context, query types.UsersQuery) ([]types.User, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]types.User), args.Error(1)
+}
+
+func (m *MockAdminService) DisableUser(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) EnableUser(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) ForcePasswordReset(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) SetRole(ctx context.Context, id uuid.UUID, req types.RoleRequestBody) error {
+	args := m.Called(ctx, id, req)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) GetAuditEvents(ctx context.Context, query types.AuditQuery) ([]types.AuditEvent, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]types.AuditEvent), args.Error(1)
+}
+
+func (m *MockAdminService) VerifyAuditLog(ctx context.Context) (*types.AuditVerification, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*types.AuditVerification), args.Error(1)
+}
+
+const missingUserId = "9b2f4c1d-7e3a-4f6b-8c5d-2a1e0f9d8c7b"
+
+func TestAdminUsers(t *testing.T) {
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService)
+
+	user := uuid.MustParse(UUIDtest)
+	missing := uuid.MustParse(missingUserId)
+
+	mockService.On("GetUsers", mock.Anything, types.UsersQuery{Limit: 50}).Return([]types.User{}, nil)
+	mockService.On("DisableUser", mock.Anything, user).Return(nil)
+	mockService.On("DisableUser", mock.Anything, missing).Return(types.ErrUserNotFound)
+	mockService.On("EnableUser", mock.Anything, user).Return(nil)
+	mockService.On("EnableUser", mock.Anything, missing).Return(types.ErrUserNotFound)
+	mockService.On("ForcePasswordReset", mock.Anything, user).Return(nil)
+	mockService.On("ForcePasswordReset", mock.Anything, missing).Return(types.ErrUserNotFound)
+	mockService.On("SetRole", mock.Anything, user, types.RoleRequestBody{Role: types.RoleAdmin}).Return(nil)
+	mockService.On("SetRole", mock.Anything, user, types.RoleRequestBody{Role: "owner"}).Return(types.ErrInvalidRole)
+	mockService.On("SetRole", mock.Anything, missing, types.RoleRequestBody{Role: types.RoleUser}).Return(types.ErrUserNotFound)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		inputJSON      string
+		expectedStatus int
+	}{
+		{"Get Users", http.MethodGet, "/admin/users", "", http.StatusOK},
+		{"Disable User", http.MethodPost, "/admin/users/" + UUIDtest + "/disable", "", http.StatusOK},
+		{"Disable Unknown User", http.MethodPost, "/admin/users/" + missingUserId + "/disable", "", http.StatusNotFound},
+		{"Disable Invalid Id", http.MethodPost, "/admin/users/someone/disable", "", http.StatusBadRequest},
+		{"Enable User", http.MethodPost, "/admin/users/" + UUIDtest + "/enable", "", http.StatusOK},
+		{"Enable Unknown User", http.MethodPost, "/admin/users/" + missingUserId + "/enable", "", http.StatusNotFound},
+		{"Force Password Reset", http.MethodPost, "/admin/users/" + UUIDtest + "/password-reset", "", http.StatusOK},
+		{"Force Password Reset Unknown User", http.MethodPost, "/admin/users/" + missingUserId + "/password-reset", "", http.StatusNotFound},
+		{"Grant Admin", http.MethodPut, "/admin/users/" + UUIDtest + "/role", `{"role":"admin"}`, http.StatusOK},
+		{"Unknown Role", http.MethodPut, "/admin/users/" + UUIDtest + "/role", `{"role":"owner"}`, http.StatusBadRequest},
+		{"Role Of Unknown User", http.MethodPut, "/admin/users/" + missingUserId + "/role", `{"role":"user"}`, http.StatusNotFound},
+		{"Invalid Role Body", http.MethodPut, "/admin/users/" + UUIDtest + "/role", `{"role":`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/admin", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAdminAuditEvents(t *testing.T) {
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService)
+
+	actor := uuid.MustParse(UUIDtest)
+	from := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	brokenAt := int64(42)
+
+	mockService.On("GetAuditEvents", mock.Anything, types.AuditQuery{Limit: 50}).Return([]types.AuditEvent{}, nil)
+	mockService.On("GetAuditEvents", mock.Anything, types.AuditQuery{Type: types.AuditLoginFailed, ActorId: &actor, Limit: 10, Offset: 20}).Return([]types.AuditEvent{}, nil)
+	mockService.On("GetAuditEvents", mock.Anything, types.AuditQuery{From: &from, To: &to, Limit: 50}).Return([]types.AuditEvent{}, nil)
+	mockService.On("GetAuditEvents", mock.Anything, types.AuditQuery{Type: "broken", Limit: 50}).Return([]types.AuditEvent(nil), errors.New("connection refused"))
+	mockService.On("VerifyAuditLog", mock.Anything).Return(&types.AuditVerification{Valid: false, Checked: 42, BrokenAt: &brokenAt}, nil).Once()
+	mockService.On("VerifyAuditLog", mock.Anything).Return(&types.AuditVerification{Valid: true, Checked: 42}, nil).Once()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Get Audit Events", "/admin/audit-events", http.StatusOK, ""},
+		{"Filter Audit Events", "/admin/audit-events?type=login.failed&actor_id=" + UUIDtest + "&limit=10&offset=20", http.StatusOK, ""},
+		{"Time Range", "/admin/audit-events?from=2026-10-18T00:00:00Z&to=2026-10-19T00:00:00Z", http.StatusOK, ""},
+		{"Invalid Actor Id", "/admin/audit-events?actor_id=someone", http.StatusBadRequest, ""},
+		{"Invalid From Time", "/admin/audit-events?from=yesterday", http.StatusBadRequest, ""},
+		{"Store Failure", "/admin/audit-events?type=broken", http.StatusInternalServerError, ""},
+		{"Tampered Audit Log", "/admin/audit-events/verify", http.StatusOK, `"broken_at":42`},
+		{"Intact Audit Log", "/admin/audit-events/verify", http.StatusOK, `"valid":true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/admin", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -18,10 +22,35 @@ func NewAuthHandler(authService types.AuthServices) *AuthHandler {
 	return &AuthHandler{authService: authService}
 }
 
-func (h *AuthHandler) RegisterRoute(r chi.Router) {
+func (h *AuthHandler) RegisterRoute(r chi.Router, authMiddlewares ...func(http.Handler) http.Handler) {
 	// handle the request
 	r.Post("/register", h.Register)
 	r.Post("/login", h.Login)
+	r.Post("/login/mfa", h.LoginMfa)
+	r.Post("/refresh", h.Refresh)
+	r.Post("/verify", h.Verify)
+	r.Post("/resend-verification", h.ResendVerification)
+	r.Post("/password/forgot", h.ForgotPassword)
+	r.Post("/password/reset", h.ResetPassword)
+	r.Post("/magic-link", h.RequestMagicLink)
+	r.Post("/magic-link/consume", h.ConsumeMagicLink)
+
+	// routes that need an authenticated user
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddlewares...)
+		r.Post("/logout", h.Logout)
+		r.Post("/logout/all", h.LogoutAll)
+		r.Post("/2fa/enroll", h.EnrollTotp)
+		r.Post("/2fa/confirm", h.ConfirmTotp)
+		r.Post("/2fa/disable", h.DisableTotp)
+		r.Post("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+		r.Get("/sessions", h.GetSessions)
+		r.Delete("/sessions/{id}", h.DeleteSession)
+		r.Get("/me", h.GetMe)
+		r.Patch("/me", h.UpdateMe)
+		r.Delete("/me", h.DeleteMe)
+		r.Post("/password/change", h.ChangePassword)
+	})
 }
 
 // Auth godoc
@@ -50,6 +79,10 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	res, err := h.authService.Register(r.Context(), user)
 
 	if err != nil {
+		if errors.Is(err, libs.ErrWeakPassword) {
+			libs.BadRequest(w, err.Error())
+			return
+		}
 		libs.InternalServerError(w, err.Error())
 		return
 	}
@@ -60,13 +93,16 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // Auth godoc
 //
 //	@Summary		Login an account
-//	@Description	Login an account with email and password
+//	@Description	Login an account with email and password, accounts with 2fa enabled receive an mfa_token for /auth/login/mfa instead of the token pair
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			body	body		types.UserRequestBody	true	"User object that needs to be registered"
 //	@Success		200		{object}	libs.Response
 //	@Failure		400		{object}	libs.Response
+//	@Failure		401		{object}	libs.Response
+//	@Failure		403		{object}	libs.Response
+//	@Failure		429		{object}	libs.Response
 //	@Failure		500		{object}	libs.Response
 //	@Router			/auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -91,9 +127,292 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			libs.InternalServerError(w, "Request timeout")
 			return
 		}
+
+		var locked *types.LockedError
+
+		if errors.As(err, &locked) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			libs.WriteJSON(w, false, http.StatusTooManyRequests, err.Error(), nil)
+			return
+		}
+
+		if errors.Is(err, types.ErrInvalidCredentials) {
+			libs.Unauthorized(w, err.Error())
+			return
+		}
+
+		if errors.Is(err, types.ErrAccountDisabled) {
+			libs.Forbidden(w, err.Error())
+			return
+		}
 		libs.InternalServerError(w, err.Error())
 		return
 	}
 
 	libs.WriteJSON(w, true, http.StatusOK, "User logged in successfully", res)
 }
+
+// Auth godoc
+//
+//	@Summary		Refresh tokens
+//	@Description	Exchange a refresh token for a new token pair, revoking the one presented
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.RefreshRequestBody	true	"Refresh token issued at login or by a previous refresh"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		401		{object}	libs.Response
+//	@Failure		403		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.RefreshRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.RefreshToken == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.authService.Refresh(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidToken) || errors.Is(err, types.ErrRefreshTokenReused) {
+			libs.Unauthorized(w, err.Error())
+			return
+		}
+
+		if errors.Is(err, types.ErrAccountDisabled) {
+			libs.Forbidden(w, err.Error())
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Token refreshed successfully", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Logout
+//	@Description	revoke the current access token and end its session, or the session of the refresh token when one is given
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.LogoutRequestBody	false	"Refresh token of the session to end"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.LogoutRequestBody
+
+	// the body is optional
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.Logout(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidToken) {
+			libs.BadRequest(w, "Invalid refresh token")
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User logged out successfully", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Logout everywhere
+//	@Description	revoke every token issued to the current user before the given time, defaults to now. Personal access tokens created before it are deleted
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.LogoutAllRequestBody	false	"Revocation cutoff"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/logout/all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.LogoutAllRequestBody
+
+	// the body is optional
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.LogoutAll(r.Context(), req)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User logged out from all devices successfully", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Verify email
+//	@Description	verify the email address of an account with the token sent by email
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.VerifyRequestBody	true	"Verification token"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/verify [post]
+func (h *AuthHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.VerifyRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Token == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.Verify(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidToken) {
+			libs.BadRequest(w, "Invalid or expired verification token")
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Email verified successfully", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Resend verification email
+//	@Description	send a new verification email, the response does not reveal whether the account exists
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.ResendVerificationRequestBody	true	"Email of the account"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.ResendVerificationRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Email == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.ResendVerification(r.Context(), req)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "If the account exists and is not verified, a verification email has been sent", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Forgot password
+//	@Description	email a password reset link, the response does not reveal whether the account exists
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.ForgotPasswordRequestBody	true	"Email of the account"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.ForgotPasswordRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Email == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.ForgotPassword(r.Context(), req)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "If the account exists, a password reset email has been sent", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Reset password
+//	@Description	set a new password with a reset token, every existing session and personal access token of the user is revoked
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.ResetPasswordRequestBody	true	"Reset token and new password"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.ResetPasswordRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Token == "" || req.Password == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.ResetPassword(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidToken) {
+			libs.BadRequest(w, "Invalid or expired reset token")
+			return
+		}
+
+		if errors.Is(err, libs.ErrWeakPassword) {
+			libs.BadRequest(w, err.Error())
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Password reset successfully", nil)
+}
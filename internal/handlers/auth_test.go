@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -30,6 +31,106 @@ func (m *MockAuthService) Login(ctx context.Context, user types.UserRequestBody)
 	return args.Get(0).(*types.User), args.Error(1)
 }
 
+func (m *MockAuthService) Refresh(ctx context.Context, req types.RefreshRequestBody) (*types.Token, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.Token), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, req types.LogoutRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(ctx context.Context, req types.LogoutAllRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Verify(ctx context.Context, req types.VerifyRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResendVerification(ctx context.Context, req types.ResendVerificationRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ForgotPassword(ctx context.Context, req types.ForgotPasswordRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, req types.ResetPasswordRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RequestMagicLink(ctx context.Context, req types.MagicLinkRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ConsumeMagicLink(ctx context.Context, req types.MagicLinkConsumeRequestBody) (*types.User, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
+func (m *MockAuthService) LoginMfa(ctx context.Context, req types.MfaLoginRequestBody) (*types.User, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
+func (m *MockAuthService) EnrollTotp(ctx context.Context) (*types.TotpEnrollment, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*types.TotpEnrollment), args.Error(1)
+}
+
+func (m *MockAuthService) ConfirmTotp(ctx context.Context, req types.TotpCodeRequestBody) (*types.RecoveryCodes, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.RecoveryCodes), args.Error(1)
+}
+
+func (m *MockAuthService) DisableTotp(ctx context.Context, req types.TotpCodeRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RegenerateRecoveryCodes(ctx context.Context, req types.TotpCodeRequestBody) (*types.RecoveryCodes, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.RecoveryCodes), args.Error(1)
+}
+
+func (m *MockAuthService) GetSessions(ctx context.Context) ([]types.Session, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]types.Session), args.Error(1)
+}
+
+func (m *MockAuthService) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) GetMe(ctx context.Context) (*types.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
+func (m *MockAuthService) UpdateMe(ctx context.Context, req types.ProfilePatchRequestBody) (*types.User, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
+func (m *MockAuthService) ChangePassword(ctx context.Context, req types.ChangePasswordRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) DeleteMe(ctx context.Context, req types.DeleteAccountRequestBody) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
 const UUIDtest = "3162d3f0-5532-402d-ab85-28946a279cac"
 
 func TestRegister(t *testing.T) {
@@ -93,6 +194,292 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestRefresh(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+
+	tests := []struct {
+		name           string
+		inputJSON      string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:      "Successful Refresh",
+			inputJSON: `{"refresh_token":"valid-token"}`,
+			mockBehavior: func() {
+				mockService.On("Refresh", mock.Anything, types.RefreshRequestBody{RefreshToken: "valid-token"}).
+					Return(&types.Token{AccessToken: "access", RefreshToken: "refresh"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing Token",
+			inputJSON:      `{}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Reused Token",
+			inputJSON: `{"refresh_token":"reused-token"}`,
+			mockBehavior: func() {
+				mockService.On("Refresh", mock.Anything, types.RefreshRequestBody{RefreshToken: "reused-token"}).
+					Return((*types.Token)(nil), types.ErrRefreshTokenReused)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, _ := http.NewRequest("POST", "/refresh", bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/refresh", handler.Refresh)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestLoginFailures(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+
+	tests := []struct {
+		name           string
+		inputJSON      string
+		mockBehavior   func()
+		expectedStatus int
+		retryAfter     string
+	}{
+		{
+			name:      "Invalid Credentials",
+			inputJSON: `{"email":"unknown@example.com","password":"wrong"}`,
+			mockBehavior: func() {
+				mockService.On("Login", mock.Anything, types.UserRequestBody{Email: "unknown@example.com", Password: "wrong"}).
+					Return((*types.User)(nil), types.ErrInvalidCredentials)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:      "Locked Out",
+			inputJSON: `{"email":"locked@example.com","password":"wrong"}`,
+			mockBehavior: func() {
+				mockService.On("Login", mock.Anything, types.UserRequestBody{Email: "locked@example.com", Password: "wrong"}).
+					Return((*types.User)(nil), &types.LockedError{RetryAfter: 1500 * time.Millisecond})
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			retryAfter:     "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, _ := http.NewRequest("POST", "/login", bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/login", handler.Login)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.retryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestConsumeMagicLink(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+
+	tests := []struct {
+		name           string
+		inputJSON      string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:      "Successful Sign In",
+			inputJSON: `{"token":"valid-token"}`,
+			mockBehavior: func() {
+				mockService.On("ConsumeMagicLink", mock.Anything, types.MagicLinkConsumeRequestBody{Token: "valid-token"}).
+					Return(&types.User{Id: uuid.MustParse(UUIDtest), Token: types.Token{AccessToken: "access", RefreshToken: "refresh"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing Token",
+			inputJSON:      `{}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Used Token",
+			inputJSON: `{"token":"used-token"}`,
+			mockBehavior: func() {
+				mockService.On("ConsumeMagicLink", mock.Anything, types.MagicLinkConsumeRequestBody{Token: "used-token"}).
+					Return((*types.User)(nil), types.ErrInvalidToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:      "Disabled Account",
+			inputJSON: `{"token":"disabled-token"}`,
+			mockBehavior: func() {
+				mockService.On("ConsumeMagicLink", mock.Anything, types.MagicLinkConsumeRequestBody{Token: "disabled-token"}).
+					Return((*types.User)(nil), types.ErrAccountDisabled)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, _ := http.NewRequest("POST", "/magic-link/consume", bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/magic-link/consume", handler.ConsumeMagicLink)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+
+	tests := []struct {
+		name           string
+		inputJSON      string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:      "With Current Password",
+			inputJSON: `{"current_password":"password123","new_password":"new-password123"}`,
+			mockBehavior: func() {
+				mockService.On("ChangePassword", mock.Anything, types.ChangePasswordRequestBody{CurrentPassword: "password123", NewPassword: "new-password123"}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "First Password After A Fresh Login",
+			inputJSON: `{"new_password":"new-password123"}`,
+			mockBehavior: func() {
+				mockService.On("ChangePassword", mock.Anything, types.ChangePasswordRequestBody{NewPassword: "new-password123"}).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "First Password After An Old Login",
+			inputJSON: `{"new_password":"new-password123"}`,
+			mockBehavior: func() {
+				mockService.On("ChangePassword", mock.Anything, types.ChangePasswordRequestBody{NewPassword: "new-password123"}).Return(types.ErrReauthRequired).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Missing New Password",
+			inputJSON:      `{"current_password":"password123"}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, _ := http.NewRequest("POST", "/password/change", bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/password/change", handler.ChangePassword)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestDeleteMe(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+
+	tests := []struct {
+		name           string
+		inputJSON      string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:      "With Password",
+			inputJSON: `{"password":"password123"}`,
+			mockBehavior: func() {
+				mockService.On("DeleteMe", mock.Anything, types.DeleteAccountRequestBody{Password: "password123"}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Wrong Password",
+			inputJSON: `{"password":"wrong"}`,
+			mockBehavior: func() {
+				mockService.On("DeleteMe", mock.Anything, types.DeleteAccountRequestBody{Password: "wrong"}).Return(types.ErrInvalidPassword)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:      "Without Password After A Fresh Login",
+			inputJSON: `{}`,
+			mockBehavior: func() {
+				mockService.On("DeleteMe", mock.Anything, types.DeleteAccountRequestBody{}).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Without Password After An Old Login",
+			inputJSON: `{}`,
+			mockBehavior: func() {
+				mockService.On("DeleteMe", mock.Anything, types.DeleteAccountRequestBody{}).Return(types.ErrReauthRequired).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Invalid Body",
+			inputJSON:      `{"password":`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, _ := http.NewRequest("DELETE", "/me", bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Delete("/me", handler.DeleteMe)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
 // func TestLogin(t *testing.T) {
 // 	mockService := new(MockAuthService)
 // 	handler := NewAuthHandler(mockService)
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type ChecklistHandler struct {
+	service types.ChecklistServices
+}
+
+func NewChecklistHandler(service types.ChecklistServices) *ChecklistHandler {
+	return &ChecklistHandler{service: service}
+}
+
+// RegisterRoute registers the checklist routes under the route of a todo
+func (h *ChecklistHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/", h.Get)
+	r.Post("/", h.Add)
+	r.Put("/order", h.Reorder)
+	r.Patch("/{itemId}", h.Update)
+	r.Delete("/{itemId}", h.Delete)
+}
+
+// Checklist godoc
+//
+//	@Summary		Get the checklist of a todo
+//	@Description	get the items of a todo by position with its progress
+//	@Tags			checklist
+//	@Produce		json
+//	@Param			id				path	string	true	"Todo id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id}/checklist [get]
+func (h *ChecklistHandler) Get(w http.ResponseWriter, r *http.Request) {
+	todoId, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
+	res, err := h.service.Get(r.Context(), todoId)
+
+	if err != nil {
+		writeChecklistError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Checklist retrieved successfully", res)
+}
+
+// Checklist godoc
+//
+//	@Summary		Add a checklist item
+//	@Description	add an item at the end of the checklist of a todo, an auto_complete todo is reopened
+//	@Tags			checklist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string								true	"Todo id"
+//	@Param			body			body	types.ChecklistItemPostRequestBody	true	"Item title"
+//	@Param			X-Workspace-ID	header	string								false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id}/checklist [post]
+func (h *ChecklistHandler) Add(w http.ResponseWriter, r *http.Request) {
+	todoId, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
+	var req types.ChecklistItemPostRequestBody
+
+	err = libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Add(r.Context(), todoId, req)
+
+	if err != nil {
+		writeChecklistError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusCreated, "Checklist item added successfully", res)
+}
+
+// Checklist godoc
+//
+//	@Summary		Update a checklist item
+//	@Description	rename an item or toggle it with done, an auto_complete todo is completed once every item is done
+//	@Tags			checklist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string								true	"Todo id"
+//	@Param			itemId			path	string								true	"Item id"
+//	@Param			body			body	types.ChecklistItemPatchRequestBody	true	"Fields to change, missing fields are kept"
+//	@Param			X-Workspace-ID	header	string								false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id}/checklist/{itemId} [patch]
+func (h *ChecklistHandler) Update(w http.ResponseWriter, r *http.Request) {
+	todoId, id, ok := checklistItemIds(w, r)
+
+	if !ok {
+		return
+	}
+
+	var req types.ChecklistItemPatchRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Update(r.Context(), todoId, id, req)
+
+	if err != nil {
+		writeChecklistError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Checklist item updated successfully", res)
+}
+
+// Checklist godoc
+//
+//	@Summary		Reorder a checklist
+//	@Description	give the items of a todo the order of item_ids, which must list every item once
+//	@Tags			checklist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string							true	"Todo id"
+//	@Param			body			body	types.ChecklistOrderRequestBody	true	"Every item id in the new order"
+//	@Param			X-Workspace-ID	header	string							false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id}/checklist/order [put]
+func (h *ChecklistHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	todoId, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
+	var req types.ChecklistOrderRequestBody
+
+	err = libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Reorder(r.Context(), todoId, req)
+
+	if err != nil {
+		writeChecklistError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Checklist reordered successfully", res)
+}
+
+// Checklist godoc
+//
+//	@Summary		Delete a checklist item
+//	@Description	delete an item of the checklist of a todo
+//	@Tags			checklist
+//	@Produce		json
+//	@Param			id				path	string	true	"Todo id"
+//	@Param			itemId			path	string	true	"Item id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id}/checklist/{itemId} [delete]
+func (h *ChecklistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	todoId, id, ok := checklistItemIds(w, r)
+
+	if !ok {
+		return
+	}
+
+	res, err := h.service.Delete(r.Context(), todoId, id)
+
+	if err != nil {
+		writeChecklistError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Checklist item deleted successfully", res)
+}
+
+// checklistItemIds reads the todo and item ids of the path, a bad request is
+// written when one is invalid
+func checklistItemIds(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	todoId, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "itemId"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid checklist item id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return todoId, id, true
+}
+
+func writeChecklistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrTodoNotFound), errors.Is(err, types.ErrChecklistItemNotFound), errors.Is(err, types.ErrWorkspaceNotFound):
+		libs.NotFound(w, err.Error())
+	case errors.Is(err, types.ErrWorkspaceForbidden):
+		libs.Forbidden(w, err.Error())
+	case errors.Is(err, types.ErrInvalidChecklistTitle), errors.Is(err, types.ErrInvalidChecklistOrder):
+		libs.BadRequest(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockChecklistService is a mock implementation of the ChecklistService
+type MockChecklistService struct {
+	mock.Mock
+}
+
+func (m *MockChecklistService) Get(ctx context.Context, todoId uuid.UUID) (*types.Checklist, error) {
+	args := m.Called(ctx, todoId)
+	return args.Get(0).(*types.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistService) Add(ctx context.Context, todoId uuid.UUID, req types.ChecklistItemPostRequestBody) (*types.Checklist, error) {
+	args := m.Called(ctx, todoId, req)
+	return args.Get(0).(*types.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistService) Update(ctx context.Context, todoId uuid.UUID, id uuid.UUID, req types.ChecklistItemPatchRequestBody) (*types.Checklist, error) {
+	args := m.Called(ctx, todoId, id, req)
+	return args.Get(0).(*types.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistService) Reorder(ctx context.Context, todoId uuid.UUID, req types.ChecklistOrderRequestBody) (*types.Checklist, error) {
+	args := m.Called(ctx, todoId, req)
+	return args.Get(0).(*types.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistService) Delete(ctx context.Context, todoId uuid.UUID, id uuid.UUID) (*types.Checklist, error) {
+	args := m.Called(ctx, todoId, id)
+	return args.Get(0).(*types.Checklist), args.Error(1)
+}
+
+func TestChecklist(t *testing.T) {
+	mockService := new(MockChecklistService)
+	handler := NewChecklistHandler(mockService)
+
+	todoId := uuid.MustParse(UUIDtest)
+	itemId := uuid.MustParse(missingTodoId)
+	done := true
+	checklist := &types.Checklist{TodoId: todoId, Completed: true, AutoComplete: true, Progress: types.ChecklistProgress{Done: 1, Total: 1}}
+
+	mockService.On("Get", mock.Anything, todoId).Return(checklist, nil)
+	mockService.On("Add", mock.Anything, todoId, types.ChecklistItemPostRequestBody{Title: "Buy milk"}).Return(checklist, nil)
+	mockService.On("Add", mock.Anything, todoId, types.ChecklistItemPostRequestBody{Title: " "}).Return((*types.Checklist)(nil), types.ErrInvalidChecklistTitle)
+	mockService.On("Update", mock.Anything, todoId, itemId, types.ChecklistItemPatchRequestBody{Done: &done}).Return(checklist, nil)
+	mockService.On("Reorder", mock.Anything, todoId, types.ChecklistOrderRequestBody{ItemIds: []uuid.UUID{itemId}}).Return(checklist, nil)
+	mockService.On("Reorder", mock.Anything, todoId, types.ChecklistOrderRequestBody{ItemIds: []uuid.UUID{itemId, itemId}}).Return((*types.Checklist)(nil), types.ErrInvalidChecklistOrder)
+	mockService.On("Delete", mock.Anything, todoId, itemId).Return((*types.Checklist)(nil), types.ErrChecklistItemNotFound)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		inputJSON      string
+		expectedStatus int
+	}{
+		{"Get Checklist", http.MethodGet, "/todos/" + UUIDtest + "/checklist/", "", http.StatusOK},
+		{"Invalid Todo Id", http.MethodGet, "/todos/not-a-uuid/checklist/", "", http.StatusBadRequest},
+		{"Add Item", http.MethodPost, "/todos/" + UUIDtest + "/checklist/", `{"title":"Buy milk"}`, http.StatusCreated},
+		{"Add Blank Item", http.MethodPost, "/todos/" + UUIDtest + "/checklist/", `{"title":" "}`, http.StatusBadRequest},
+		{"Toggle Item", http.MethodPatch, "/todos/" + UUIDtest + "/checklist/" + missingTodoId, `{"done":true}`, http.StatusOK},
+		{"Invalid Item Id", http.MethodPatch, "/todos/" + UUIDtest + "/checklist/not-a-uuid", `{"done":true}`, http.StatusBadRequest},
+		{"Reorder", http.MethodPut, "/todos/" + UUIDtest + "/checklist/order", `{"item_ids":["` + missingTodoId + `"]}`, http.StatusOK},
+		{"Reorder Repeated Item", http.MethodPut, "/todos/" + UUIDtest + "/checklist/order", `{"item_ids":["` + missingTodoId + `","` + missingTodoId + `"]}`, http.StatusBadRequest},
+		{"Delete Missing Item", http.MethodDelete, "/todos/" + UUIDtest + "/checklist/" + missingTodoId, "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/todos/{id}/checklist", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
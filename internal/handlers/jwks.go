@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type JwksHandler struct {
+	keys *libs.KeySet
+}
+
+func NewJwksHandler(keys *libs.KeySet) *JwksHandler {
+	return &JwksHandler{keys: keys}
+}
+
+func (h *JwksHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/.well-known/jwks.json", h.Get)
+}
+
+// Get writes the public verification keys as a plain RFC 7517 key set, without the
+// response envelope, so standard JWT libraries can consume it
+func (h *JwksHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// Auth godoc
+//
+//	@Summary		Request a sign-in link
+//	@Description	email a single use sign-in link, an account without password is created on first use. The response does not reveal whether the account exists
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.MagicLinkRequestBody	true	"Email to sign in with"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.MagicLinkRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Email == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.RequestMagicLink(r.Context(), req)
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "A sign-in link has been sent", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Sign in with a link
+//	@Description	exchange a sign-in link token for a token pair, accounts with 2fa enabled receive an mfa_token for /auth/login/mfa instead
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.MagicLinkConsumeRequestBody	true	"Token of the sign-in link"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		401		{object}	libs.Response
+//	@Failure		403		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/magic-link/consume [post]
+func (h *AuthHandler) ConsumeMagicLink(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.MagicLinkConsumeRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Token == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.authService.ConsumeMagicLink(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidToken) {
+			libs.Unauthorized(w, "Invalid or expired sign-in link")
+			return
+		}
+
+		if errors.Is(err, types.ErrAccountDisabled) {
+			libs.Forbidden(w, err.Error())
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User logged in successfully", res)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// Auth godoc
+//
+//	@Summary		Complete a 2fa login
+//	@Description	exchange the mfa_token returned by login and a totp or recovery code for the token pair, each mfa_token allows a single attempt
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		types.MfaLoginRequestBody	true	"Challenge and code"
+//	@Success		200		{object}	libs.Response
+//	@Failure		400		{object}	libs.Response
+//	@Failure		401		{object}	libs.Response
+//	@Failure		403		{object}	libs.Response
+//	@Failure		500		{object}	libs.Response
+//	@Router			/auth/login/mfa [post]
+func (h *AuthHandler) LoginMfa(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.MfaLoginRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.MfaToken == "" || req.Code == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.authService.LoginMfa(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidToken) || errors.Is(err, types.ErrInvalidCode) {
+			libs.Unauthorized(w, err.Error())
+			return
+		}
+
+		if errors.Is(err, types.ErrAccountDisabled) {
+			libs.Forbidden(w, err.Error())
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User logged in successfully", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Start 2fa enrollment
+//	@Description	generate a totp secret and otpauth uri, 2fa is enabled once confirmed
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/2fa/enroll [post]
+func (h *AuthHandler) EnrollTotp(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authService.EnrollTotp(r.Context())
+
+	if err != nil {
+		writeMfaError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Two-factor enrollment started", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Confirm 2fa enrollment
+//	@Description	enable 2fa with a code from the authenticator, returns the recovery codes
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.TotpCodeRequestBody	true	"Totp code"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/2fa/confirm [post]
+func (h *AuthHandler) ConfirmTotp(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.TotpCodeRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Code == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.authService.ConfirmTotp(r.Context(), req)
+
+	if err != nil {
+		writeMfaError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Two-factor authentication enabled", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Disable 2fa
+//	@Description	disable 2fa with a totp or recovery code
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.TotpCodeRequestBody	true	"Totp or recovery code"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/2fa/disable [post]
+func (h *AuthHandler) DisableTotp(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.TotpCodeRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Code == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.authService.DisableTotp(r.Context(), req)
+
+	if err != nil {
+		writeMfaError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// Auth godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	replace every recovery code, needs a totp or recovery code
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.TotpCodeRequestBody	true	"Totp or recovery code"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.TotpCodeRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || req.Code == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.authService.RegenerateRecoveryCodes(r.Context(), req)
+
+	if err != nil {
+		writeMfaError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Recovery codes regenerated", res)
+}
+
+func writeMfaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrInvalidCode):
+		libs.Unauthorized(w, err.Error())
+	case errors.Is(err, types.ErrMfaEnabled), errors.Is(err, types.ErrMfaNotEnabled), errors.Is(err, types.ErrMfaNotEnrolled):
+		libs.BadRequest(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/odev-swe/todoapp/internal/oidc"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type OidcHandler struct {
+	service types.OidcServices
+}
+
+func NewOidcHandler(service types.OidcServices) *OidcHandler {
+	return &OidcHandler{service: service}
+}
+
+// oidcBindingCookie ties a login to the browser that started it, the
+// callback of another browser is refused
+const oidcBindingCookie = "oidc_binding"
+
+func (h *OidcHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/{provider}/login", h.Login)
+	r.Get("/{provider}/callback", h.Callback)
+}
+
+// Oidc godoc
+//
+//	@Summary		Login with an identity provider
+//	@Description	redirect to the identity provider to start an authorization code flow with pkce, the login is bound to the browser with a cookie
+//	@Tags			auth
+//	@Param			provider	path	string	true	"Provider name"
+//	@Success		302
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/oidc/{provider}/login [get]
+func (h *OidcHandler) Login(w http.ResponseWriter, r *http.Request) {
+	url, binding, err := h.service.AuthorizationUrl(r.Context(), chi.URLParam(r, "provider"))
+
+	if err != nil {
+		if errors.Is(err, types.ErrUnknownProvider) {
+			libs.NotFound(w, err.Error())
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	// lax so the cookie comes back with the redirect of the provider
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    binding,
+		Path:     strings.TrimSuffix(r.URL.Path, "/login"),
+		MaxAge:   int(types.OidcStateTTL.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// Oidc godoc
+//
+//	@Summary		Identity provider callback
+//	@Description	complete the authorization code flow in the browser that started it, links the identity to the account with the same verified email or creates one
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider	path		string	true	"Provider name"
+//	@Param			code		query		string	true	"Authorization code"
+//	@Param			state		query		string	true	"State returned by the provider"
+//	@Success		200			{object}	libs.Response
+//	@Failure		400			{object}	libs.Response
+//	@Failure		401			{object}	libs.Response
+//	@Failure		403			{object}	libs.Response
+//	@Failure		404			{object}	libs.Response
+//	@Failure		500			{object}	libs.Response
+//	@Router			/auth/oidc/{provider}/callback [get]
+func (h *OidcHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// the user denied access or the provider failed
+	if e := query.Get("error"); e != "" {
+		libs.BadRequest(w, "Identity provider error: "+e)
+		return
+	}
+
+	if query.Get("code") == "" || query.Get("state") == "" {
+		libs.BadRequest(w, "Missing code or state")
+		return
+	}
+
+	cookie, err := r.Cookie(oidcBindingCookie)
+
+	if err != nil {
+		libs.Unauthorized(w, types.ErrInvalidState.Error())
+		return
+	}
+
+	// the binding is single use like the state
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Path:     strings.TrimSuffix(r.URL.Path, "/callback"),
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	res, err := h.service.Callback(r.Context(), chi.URLParam(r, "provider"), query.Get("code"), query.Get("state"), cookie.Value)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrUnknownProvider):
+			libs.NotFound(w, err.Error())
+		case errors.Is(err, types.ErrInvalidState), errors.Is(err, types.ErrEmailNotVerified),
+			errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, oidc.ErrInvalidNonce):
+			libs.Unauthorized(w, err.Error())
+		case errors.Is(err, types.ErrAccountDisabled):
+			libs.Forbidden(w, err.Error())
+		default:
+			libs.InternalServerError(w, err.Error())
+		}
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "User logged in successfully", res)
+}
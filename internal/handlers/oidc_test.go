@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOidcService is a mock implementation of the OidcService
+type MockOidcService struct {
+	mock.Mock
+}
+
+func (m *MockOidcService) AuthorizationUrl(ctx context.Context, provider string) (string, string, error) {
+	args := m.Called(ctx, provider)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockOidcService) Callback(ctx context.Context, provider string, code string, state string, binding string) (*types.User, error) {
+	args := m.Called(ctx, provider, code, state, binding)
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
+func TestOidc(t *testing.T) {
+	mockService := new(MockOidcService)
+	handler := NewOidcHandler(mockService)
+
+	mockService.On("AuthorizationUrl", mock.Anything, "google").Return("https://accounts.example.com/auth", "binding", nil)
+	mockService.On("AuthorizationUrl", mock.Anything, "unknown").Return("", "", types.ErrUnknownProvider)
+	mockService.On("Callback", mock.Anything, "google", "code", "state", "binding").Return(&types.User{Email: "test@example.com"}, nil)
+	mockService.On("Callback", mock.Anything, "google", "code", "state", "other").Return((*types.User)(nil), types.ErrInvalidState)
+
+	tests := []struct {
+		name           string
+		path           string
+		binding        string
+		expectedStatus int
+		expectedCookie string
+	}{
+		{"Login", "/oidc/google/login", "", http.StatusFound, "binding"},
+		{"Unknown Provider", "/oidc/unknown/login", "", http.StatusNotFound, ""},
+		{"Callback", "/oidc/google/callback?code=code&state=state", "binding", http.StatusOK, ""},
+		{"Callback Without Binding", "/oidc/google/callback?code=code&state=state", "", http.StatusUnauthorized, ""},
+		{"Callback Of Another Browser", "/oidc/google/callback?code=code&state=state", "other", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+
+			if tt.binding != "" {
+				req.AddCookie(&http.Cookie{Name: oidcBindingCookie, Value: tt.binding})
+			}
+
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/oidc", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedCookie != "" {
+				cookies := rr.Result().Cookies()
+
+				assert.Len(t, cookies, 1)
+				assert.Equal(t, tt.expectedCookie, cookies[0].Value)
+				assert.Equal(t, "/oidc/google", cookies[0].Path)
+				assert.True(t, cookies[0].Secure && cookies[0].HttpOnly)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type ProjectsHandler struct {
+	service types.ProjectsServices
+}
+
+func NewProjectsHandler(service types.ProjectsServices) *ProjectsHandler {
+	return &ProjectsHandler{service: service}
+}
+
+func (h *ProjectsHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/", h.Get)
+	r.Post("/", h.Create)
+	r.Get("/{id}", h.GetById)
+	r.Patch("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+	r.Get("/{id}/todos", h.GetTodos)
+}
+
+// Projects godoc
+//
+//	@Summary		Get projects
+//	@Description	list the projects of the workspace by position with their todo and completed counts
+//	@Tags			projects
+//	@Produce		json
+//	@Param			archived		query	bool	false	"Archived status, every project when missing"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/projects [get]
+func (h *ProjectsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	var query types.ProjectsQuery
+
+	if v := r.URL.Query().Get("archived"); v != "" {
+		archived, err := strconv.ParseBool(v)
+
+		if err != nil {
+			libs.BadRequest(w, "invalid archived filter")
+			return
+		}
+
+		query.Archived = &archived
+	}
+
+	res, err := h.service.Get(r.Context(), query)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Projects retrieved successfully", res)
+}
+
+// Projects godoc
+//
+//	@Summary		Create a project
+//	@Description	create a project, it goes after the others unless a position is given
+//	@Tags			projects
+//	@Accept			json
+//	@Produce		json
+//	@Param			body			body	types.ProjectPostRequestBody	true	"Project name, color and position, the color defaults to #808080"
+//	@Param			X-Workspace-ID	header	string							false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/projects [post]
+func (h *ProjectsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.ProjectPostRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Create(r.Context(), req)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusCreated, "Project created successfully", res)
+}
+
+// Projects godoc
+//
+//	@Summary		Get a project
+//	@Description	get a project by id with its todo and completed counts
+//	@Tags			projects
+//	@Produce		json
+//	@Param			id				path	string	true	"Project id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/projects/{id} [get]
+func (h *ProjectsHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid project id")
+		return
+	}
+
+	res, err := h.service.GetById(r.Context(), id)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Project retrieved successfully", res)
+}
+
+// Projects godoc
+//
+//	@Summary		Update a project
+//	@Description	rename, recolor, archive or move a project
+//	@Tags			projects
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string							true	"Project id"
+//	@Param			body			body	types.ProjectPatchRequestBody	true	"Fields to change, missing fields are kept"
+//	@Param			X-Workspace-ID	header	string							false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/projects/{id} [patch]
+func (h *ProjectsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid project id")
+		return
+	}
+
+	var req types.ProjectPatchRequestBody
+
+	err = libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Update(r.Context(), id, req)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Project updated successfully", res)
+}
+
+// Projects godoc
+//
+//	@Summary		Delete a project
+//	@Description	delete a project, its todos are moved to the inbox unless todos is delete
+//	@Tags			projects
+//	@Produce		json
+//	@Param			id				path	string	true	"Project id"
+//	@Param			todos			query	string	false	"What happens to the todos of the project"	Enums(inbox, delete)	default(inbox)
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/projects/{id} [delete]
+func (h *ProjectsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid project id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id, r.URL.Query().Get("todos"))
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Project deleted successfully", nil)
+}
+
+// Projects godoc
+//
+//	@Summary		Get the todos of a project
+//	@Description	get the todos of a project with the filters, sorting and cursors of the todo list
+//	@Tags			projects
+//	@Produce		json
+//	@Param			id				path	string	true	"Project id"
+//	@Param			completed		query	bool	false	"Completion status"
+//	@Param			due_before		query	string	false	"Due before, RFC 3339"
+//	@Param			due_after		query	string	false	"Due at or after, RFC 3339"
+//	@Param			created_after	query	string	false	"Created at or after, RFC 3339"
+//	@Param			overdue			query	bool	false	"Only incomplete todos past their due date"
+//	@Param			q				query	string	false	"Title substring"
+//	@Param			tag				query	string	false	"Comma separated tag names"
+//	@Param			tag_match		query	string	false	"Todos need any or all of the tags"																						Enums(any, all)	default(any)
+//	@Param			sort			query	string	false	"Comma separated columns, a leading minus sorts descending. One of title, completed, due_date, created_at, updated_at"	default(-created_at)
+//	@Param			limit			query	int		false	"Limit"																													default(10)	maximum(100)
+//	@Param			cursor			query	string	false	"next_cursor or prev_cursor of a page with the same filters and sort"
+//	@Param			total			query	bool	false	"Count the todos matching the filters"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/projects/{id}/todos [get]
+func (h *ProjectsHandler) GetTodos(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid project id")
+		return
+	}
+
+	query, err := parseTodosQuery(r.URL.Query())
+
+	if err != nil {
+		libs.BadRequest(w, err.Error())
+		return
+	}
+
+	res, err := h.service.GetTodos(r.Context(), id, query)
+
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	writeTodosPage(w, r, query, res)
+}
+
+func writeProjectError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrProjectNotFound), errors.Is(err, types.ErrWorkspaceNotFound):
+		libs.NotFound(w, err.Error())
+	case errors.Is(err, types.ErrWorkspaceForbidden):
+		libs.Forbidden(w, err.Error())
+	case errors.Is(err, types.ErrInvalidProjectName), errors.Is(err, types.ErrInvalidProjectColor), errors.Is(err, types.ErrInvalidProjectDelete), errors.Is(err, types.ErrInvalidCursor):
+		libs.BadRequest(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProjectsService is a mock implementation of the ProjectsService
+type MockProjectsService struct {
+	mock.Mock
+}
+
+func (m *MockProjectsService) Get(ctx context.Context, query types.ProjectsQuery) ([]types.Project, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]types.Project), args.Error(1)
+}
+
+func (m *MockProjectsService) Create(ctx context.Context, req types.ProjectPostRequestBody) (*types.Project, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.Project), args.Error(1)
+}
+
+func (m *MockProjectsService) GetById(ctx context.Context, id uuid.UUID) (*types.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*types.Project), args.Error(1)
+}
+
+func (m *MockProjectsService) Update(ctx context.Context, id uuid.UUID, req types.ProjectPatchRequestBody) (*types.Project, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*types.Project), args.Error(1)
+}
+
+func (m *MockProjectsService) Delete(ctx context.Context, id uuid.UUID, mode string) error {
+	args := m.Called(ctx, id, mode)
+	return args.Error(0)
+}
+
+func (m *MockProjectsService) GetTodos(ctx context.Context, id uuid.UUID, query types.TodosQuery) (*types.TodosPage, error) {
+	args := m.Called(ctx, id, query)
+	return args.Get(0).(*types.TodosPage), args.Error(1)
+}
+
+func TestProjects(t *testing.T) {
+	mockService := new(MockProjectsService)
+	handler := NewProjectsHandler(mockService)
+
+	archived := true
+	completed := false
+
+	mockService.On("Get", mock.Anything, types.ProjectsQuery{}).Return([]types.Project{}, nil)
+	mockService.On("Get", mock.Anything, types.ProjectsQuery{Archived: &archived}).Return([]types.Project{}, nil)
+	mockService.On("Create", mock.Anything, types.ProjectPostRequestBody{Name: ""}).Return((*types.Project)(nil), types.ErrInvalidProjectName)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(UUIDtest), "").Return(nil)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(UUIDtest), types.ProjectDeleteCascade).Return(nil)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(UUIDtest), "archive").Return(types.ErrInvalidProjectDelete)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(missingTodoId), "").Return(types.ErrProjectNotFound)
+	mockService.On("GetTodos", mock.Anything, uuid.MustParse(UUIDtest), types.TodosQuery{Completed: &completed, Limit: 10}).
+		Return(&types.TodosPage{Todos: []types.Todos{}, NextCursor: "next"}, nil)
+	mockService.On("GetTodos", mock.Anything, uuid.MustParse(missingTodoId), types.TodosQuery{Limit: 10}).Return((*types.TodosPage)(nil), types.ErrProjectNotFound)
+	mockService.On("GetTodos", mock.Anything, uuid.MustParse(foreignId), types.TodosQuery{Limit: 10}).Return((*types.TodosPage)(nil), types.ErrWorkspaceNotFound)
+	mockService.On("Create", mock.Anything, types.ProjectPostRequestBody{Name: "Launch"}).Return((*types.Project)(nil), types.ErrWorkspaceForbidden)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		inputJSON      string
+		expectedStatus int
+		expectedLink   string
+	}{
+		{"Get Projects", http.MethodGet, "/projects/", "", http.StatusOK, ""},
+		{"Get Archived Projects", http.MethodGet, "/projects/?archived=true", "", http.StatusOK, ""},
+		{"Invalid Archived", http.MethodGet, "/projects/?archived=maybe", "", http.StatusBadRequest, ""},
+		{"Create Without Name", http.MethodPost, "/projects/", `{"name":""}`, http.StatusBadRequest, ""},
+		{"Delete To Inbox", http.MethodDelete, "/projects/" + UUIDtest, "", http.StatusOK, ""},
+		{"Delete With Todos", http.MethodDelete, "/projects/" + UUIDtest + "?todos=delete", "", http.StatusOK, ""},
+		{"Invalid Delete Mode", http.MethodDelete, "/projects/" + UUIDtest + "?todos=archive", "", http.StatusBadRequest, ""},
+		{"Delete Missing Project", http.MethodDelete, "/projects/" + missingTodoId, "", http.StatusNotFound, ""},
+		{"Project Todos", http.MethodGet, "/projects/" + UUIDtest + "/todos?completed=false", "", http.StatusOK, `</projects/` + UUIDtest + `/todos?completed=false>; rel="first", </projects/` + UUIDtest + `/todos?completed=false&cursor=next>; rel="next"`},
+		{"Missing Project Todos", http.MethodGet, "/projects/" + missingTodoId + "/todos", "", http.StatusNotFound, ""},
+		{"Invalid Project Id", http.MethodGet, "/projects/groceries/todos", "", http.StatusBadRequest, ""},
+		{"Project Of Another Workspace", http.MethodGet, "/projects/" + foreignId + "/todos", "", http.StatusNotFound, ""},
+		{"Create As Viewer", http.MethodPost, "/projects/", `{"name":"Launch"}`, http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/projects", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedLink != "" {
+				assert.Equal(t, tt.expectedLink, rr.Header().Get("Link"))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// Auth godoc
+//
+//	@Summary		Get sessions
+//	@Description	list the devices the current user is logged in on
+//	@Tags			auth
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/sessions [get]
+func (h *AuthHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authService.GetSessions(r.Context())
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Sessions fetched successfully", res)
+}
+
+// Auth godoc
+//
+//	@Summary		Delete a session
+//	@Description	log a device out, its refresh token stops working and its access tokens are revoked
+//	@Tags			auth
+//	@Produce		json
+//	@Param			id	path	string	true	"Session id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		401	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/sessions/{id} [delete]
+func (h *AuthHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid session id")
+		return
+	}
+
+	err = h.authService.DeleteSession(r.Context(), id)
+
+	if err != nil {
+		if errors.Is(err, types.ErrSessionNotFound) {
+			libs.NotFound(w, err.Error())
+			return
+		}
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Session deleted successfully", nil)
+}
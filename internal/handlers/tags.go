@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type TagsHandler struct {
+	service types.TagsServices
+}
+
+func NewTagsHandler(service types.TagsServices) *TagsHandler {
+	return &TagsHandler{service: service}
+}
+
+func (h *TagsHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/", h.Get)
+	r.Post("/", h.Create)
+	r.Get("/{id}", h.GetById)
+	r.Patch("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+}
+
+// Tags godoc
+//
+//	@Summary		Get tags
+//	@Description	list the tags of the workspace with the number of todos using them
+//	@Tags			tags
+//	@Produce		json
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/tags [get]
+func (h *TagsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Get(r.Context())
+
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Tags retrieved successfully", res)
+}
+
+// Tags godoc
+//
+//	@Summary		Create a tag
+//	@Description	create a tag, names are unique in the workspace regardless of case
+//	@Tags			tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			body			body	types.TagPostRequestBody	true	"Tag name and color, the color defaults to #808080"
+//	@Param			X-Workspace-ID	header	string						false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		409	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/tags [post]
+func (h *TagsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.TagPostRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Create(r.Context(), req)
+
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusCreated, "Tag created successfully", res)
+}
+
+// Tags godoc
+//
+//	@Summary		Get a tag
+//	@Description	get a tag by id
+//	@Tags			tags
+//	@Produce		json
+//	@Param			id				path	string	true	"Tag id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/tags/{id} [get]
+func (h *TagsHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid tag id")
+		return
+	}
+
+	res, err := h.service.GetById(r.Context(), id)
+
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Tag retrieved successfully", res)
+}
+
+// Tags godoc
+//
+//	@Summary		Update a tag
+//	@Description	rename or recolor a tag, every todo using it shows the change
+//	@Tags			tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path	string						true	"Tag id"
+//	@Param			body			body	types.TagPatchRequestBody	true	"Fields to change, missing fields are kept"
+//	@Param			X-Workspace-ID	header	string						false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		409	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/tags/{id} [patch]
+func (h *TagsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid tag id")
+		return
+	}
+
+	var req types.TagPatchRequestBody
+
+	err = libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Update(r.Context(), id, req)
+
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Tag updated successfully", res)
+}
+
+// Tags godoc
+//
+//	@Summary		Delete a tag
+//	@Description	delete a tag and remove it from every todo
+//	@Tags			tags
+//	@Produce		json
+//	@Param			id				path	string	true	"Tag id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/tags/{id} [delete]
+func (h *TagsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid tag id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Tag deleted successfully", nil)
+}
+
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrTagNotFound), errors.Is(err, types.ErrWorkspaceNotFound):
+		libs.NotFound(w, err.Error())
+	case errors.Is(err, types.ErrWorkspaceForbidden):
+		libs.Forbidden(w, err.Error())
+	case errors.Is(err, types.ErrTagExists):
+		libs.WriteJSON(w, false, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, types.ErrInvalidTagName), errors.Is(err, types.ErrInvalidTagColor):
+		libs.BadRequest(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
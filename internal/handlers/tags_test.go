@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTagsService is a mock implementation of the TagsService
+type MockTagsService struct {
+	mock.Mock
+}
+
+func (m *MockTagsService) Get(ctx context.Context) ([]types.Tag, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]types.Tag), args.Error(1)
+}
+
+func (m *MockTagsService) Create(ctx context.Context, req types.TagPostRequestBody) (*types.Tag, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.Tag), args.Error(1)
+}
+
+func (m *MockTagsService) GetById(ctx context.Context, id uuid.UUID) (*types.Tag, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*types.Tag), args.Error(1)
+}
+
+func (m *MockTagsService) Update(ctx context.Context, id uuid.UUID, req types.TagPatchRequestBody) (*types.Tag, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*types.Tag), args.Error(1)
+}
+
+func (m *MockTagsService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestTags(t *testing.T) {
+	mockService := new(MockTagsService)
+	mockTodosService := new(MockTodosService)
+	handler := NewTagsHandler(mockService)
+	todosHandler := NewTodosHandler(mockTodosService)
+
+	id := uuid.MustParse(UUIDtest)
+	missing := uuid.MustParse(missingTodoId)
+	office := "office"
+	home := "home"
+
+	mockService.On("Get", mock.Anything).Return([]types.Tag{{Id: id, Name: "work", Color: types.DefaultTagColor, Todos: 2}}, nil)
+	mockService.On("Create", mock.Anything, types.TagPostRequestBody{Name: "work", Color: "#ff8800"}).Return(&types.Tag{Id: id, Name: "work", Color: "#ff8800"}, nil)
+	mockService.On("Create", mock.Anything, types.TagPostRequestBody{Name: "Work"}).Return((*types.Tag)(nil), types.ErrTagExists)
+	mockService.On("Create", mock.Anything, types.TagPostRequestBody{Name: "work", Color: "orange"}).Return((*types.Tag)(nil), types.ErrInvalidTagColor)
+	mockService.On("GetById", mock.Anything, id).Return(&types.Tag{Id: id, Name: "work"}, nil)
+	mockService.On("GetById", mock.Anything, missing).Return((*types.Tag)(nil), types.ErrTagNotFound)
+	mockService.On("Update", mock.Anything, id, types.TagPatchRequestBody{Name: &office}).Return(&types.Tag{Id: id, Name: office}, nil)
+	mockService.On("Update", mock.Anything, id, types.TagPatchRequestBody{Name: &home}).Return((*types.Tag)(nil), types.ErrTagExists)
+	mockService.On("Update", mock.Anything, missing, types.TagPatchRequestBody{Name: &office}).Return((*types.Tag)(nil), types.ErrTagNotFound)
+	mockService.On("Delete", mock.Anything, id).Return(nil)
+	mockService.On("Delete", mock.Anything, missing).Return(types.ErrTagNotFound)
+	mockTodosService.On("Get", mock.Anything, types.TodosQuery{Tags: []string{"work"}, Limit: 10}).Return(&types.TodosPage{Todos: []types.Todos{{Id: id, Tags: []string{"work"}}}}, nil)
+	mockTodosService.On("Get", mock.Anything, types.TodosQuery{Tags: []string{"work", "home"}, Limit: 10}).Return(&types.TodosPage{Todos: []types.Todos{}}, nil)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		inputJSON      string
+		expectedStatus int
+	}{
+		{"Get Tags", http.MethodGet, "/tags/", "", http.StatusOK},
+		{"Create Tag", http.MethodPost, "/tags/", `{"name":"work","color":"#ff8800"}`, http.StatusCreated},
+		{"Duplicate Name", http.MethodPost, "/tags/", `{"name":"Work"}`, http.StatusConflict},
+		{"Invalid Color", http.MethodPost, "/tags/", `{"name":"work","color":"orange"}`, http.StatusBadRequest},
+		{"Invalid Body", http.MethodPost, "/tags/", `{"name":`, http.StatusBadRequest},
+		{"Get Tag", http.MethodGet, "/tags/" + UUIDtest, "", http.StatusOK},
+		{"Get Missing Tag", http.MethodGet, "/tags/" + missingTodoId, "", http.StatusNotFound},
+		{"Invalid Id", http.MethodGet, "/tags/work", "", http.StatusBadRequest},
+		{"Rename Tag", http.MethodPatch, "/tags/" + UUIDtest, `{"name":"office"}`, http.StatusOK},
+		{"Rename To Existing Name", http.MethodPatch, "/tags/" + UUIDtest, `{"name":"home"}`, http.StatusConflict},
+		{"Rename Missing Tag", http.MethodPatch, "/tags/" + missingTodoId, `{"name":"office"}`, http.StatusNotFound},
+		{"Delete Tag", http.MethodDelete, "/tags/" + UUIDtest, "", http.StatusOK},
+		{"Delete Missing Tag", http.MethodDelete, "/tags/" + missingTodoId, "", http.StatusNotFound},
+		{"Todos With Tag", http.MethodGet, "/todos/?tag=work", "", http.StatusOK},
+		{"Todos With Any Tag", http.MethodGet, "/todos/?tag=work,home&tag_match=any", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/tags", handler.RegisterRoute)
+			r.Route("/todos", todosHandler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestTagsWorkspaceAccess(t *testing.T) {
+	mockService := new(MockTagsService)
+	handler := NewTagsHandler(mockService)
+
+	mockService.On("GetById", mock.Anything, uuid.MustParse(foreignId)).Return((*types.Tag)(nil), types.ErrWorkspaceNotFound)
+	mockService.On("Create", mock.Anything, types.TagPostRequestBody{Name: "work"}).Return((*types.Tag)(nil), types.ErrWorkspaceForbidden)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(UUIDtest)).Return(types.ErrWorkspaceForbidden)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		inputJSON      string
+		expectedStatus int
+	}{
+		{"Tag Of Another Workspace", http.MethodGet, "/tags/" + foreignId, "", http.StatusNotFound},
+		{"Create As Viewer", http.MethodPost, "/tags/", `{"name":"work"}`, http.StatusForbidden},
+		{"Delete As Viewer", http.MethodDelete, "/tags/" + UUIDtest, "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/tags", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -2,22 +2,29 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/odev-swe/todoapp/internal/types"
 	"github.com/odev-swe/todoapp/libs"
 )
 
+// the largest page of the todo list
+const maxTodosLimit = 100
+
 type TodosHandler struct {
 	service types.TodosServices
 }
 
-type LimitKey string
-type OffsetKey string
-
 func NewTodosHandler(service types.TodosServices) *TodosHandler {
 	return &TodosHandler{service: service}
 }
@@ -27,48 +34,106 @@ func (h *TodosHandler) RegisterRoute(r chi.Router) {
 	// post request with limit and offset parameter
 	r.Get("/", h.Get)
 	r.Post("/", h.Create)
-	r.Put("/", h.Update)
-	r.Delete("/", h.Delete)
+	r.Get("/search", h.Search)
+	r.Get("/{id}", h.GetById)
+	r.Put("/{id}", h.Update)
+	r.Patch("/{id}", h.Patch)
+	r.Delete("/{id}", h.Delete)
+
+	// the id used to be read from the body
+	r.Put("/", deprecated(h.UpdateByBody))
+	r.Delete("/", deprecated(h.DeleteByBody))
 }
 
 // Todos godoc
 //
 //	@Summary		Get todos
-//	@Description	get todos with pagination
+//	@Description	get todos with filters and sorting, pages are linked by opaque cursors returned in meta and in the Link header
 //	@Tags			todos
 //	@Accept			json
 //	@Produce		json
-//	@Param			limit	query	int	false	"Limit"		default(10)
-//	@Param			offset	query	int	false	"Offset"	default(0)
+//	@Param			completed		query	bool	false	"Completion status"
+//	@Param			due_before		query	string	false	"Due before, RFC 3339"
+//	@Param			due_after		query	string	false	"Due at or after, RFC 3339"
+//	@Param			created_after	query	string	false	"Created at or after, RFC 3339"
+//	@Param			overdue			query	bool	false	"Only incomplete todos past their due date"
+//	@Param			q				query	string	false	"Title substring"
+//	@Param			project_id		query	string	false	"Project id, inbox for the todos without a project"
+//	@Param			tag				query	string	false	"Comma separated tag names"
+//	@Param			tag_match		query	string	false	"Todos need any or all of the tags"																						Enums(any, all)	default(any)
+//	@Param			sort			query	string	false	"Comma separated columns, a leading minus sorts descending. One of title, completed, due_date, created_at, updated_at"	default(-created_at)
+//	@Param			limit			query	int		false	"Limit"																													default(10)	maximum(100)
+//	@Param			cursor			query	string	false	"next_cursor or prev_cursor of a page with the same filters and sort"
+//	@Param			total			query	bool	false	"Count the todos matching the filters"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
 //	@Security		ApiKeyAuth
 //	@Success		200	{object}	libs.Response
 //	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
 //	@Failure		500	{object}	libs.Response
 //	@Router			/todos [get]
 func (h *TodosHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query, err := parseTodosQuery(r.URL.Query())
 
-	// set default pagination
-	var limit, offset int = 10, 10
+	if err != nil {
+		libs.BadRequest(w, err.Error())
+		return
+	}
 
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsedLimit, err := strconv.Atoi(l); err == nil {
-			limit = parsedLimit
-		}
+	res, err := h.service.Get(r.Context(), query)
+
+	if err != nil {
+		writeTodoError(w, err)
+		return
 	}
-	if o := r.URL.Query().Get("offset"); o != "" {
-		if parsedOffset, err := strconv.Atoi(o); err == nil {
-			offset = parsedOffset
-		}
+
+	writeTodosPage(w, r, query, res)
+}
+
+// Todos godoc
+//
+//	@Summary		Search todos
+//	@Description	full-text search of titles and descriptions, every word matches as a prefix. Results are ranked unless sorted and come with highlighted snippets, the snippets are html escaped and only the <mark> tags are markup
+//	@Tags			todos
+//	@Produce		json
+//	@Param			q				query	string	true	"Search words"
+//	@Param			completed		query	bool	false	"Completion status"
+//	@Param			due_before		query	string	false	"Due before, RFC 3339"
+//	@Param			due_after		query	string	false	"Due at or after, RFC 3339"
+//	@Param			created_after	query	string	false	"Created at or after, RFC 3339"
+//	@Param			overdue			query	bool	false	"Only incomplete todos past their due date"
+//	@Param			project_id		query	string	false	"Project id, inbox for the todos without a project"
+//	@Param			tag				query	string	false	"Comma separated tag names"
+//	@Param			tag_match		query	string	false	"Todos need any or all of the tags"	Enums(any, all)	default(any)
+//	@Param			sort			query	string	false	"Comma separated columns, a leading minus sorts descending. One of title, completed, due_date, created_at, updated_at"
+//	@Param			limit			query	int		false	"Limit"		default(10)	maximum(100)
+//	@Param			offset			query	int		false	"Offset"	default(0)
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/search [get]
+func (h *TodosHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query, err := parseTodosQuery(r.URL.Query())
+
+	if err != nil {
+		libs.BadRequest(w, err.Error())
+		return
 	}
 
-	ctx := r.Context()
-	ctx = context.WithValue(ctx, LimitKey("limit"), limit)
-	ctx = context.WithValue(ctx, OffsetKey("offset"), offset)
+	// results are ranked per query, they are paged with an offset
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		query.Offset = o
+	}
 
-	res, err := h.service.Get(ctx)
+	res, err := h.service.Search(r.Context(), query)
 
 	if err != nil {
-		libs.InternalServerError(w, err.Error())
+		writeTodoError(w, err)
 		return
 	}
 
@@ -83,11 +148,14 @@ func (h *TodosHandler) Get(w http.ResponseWriter, r *http.Request) {
 //	@Accept			json
 //	@Produce		json
 //
-// @Param			body	body	types.TodosPostRequestBody	true	"Todo object that needs to be created"
+//	@Param			body			body	types.TodosPostRequestBody	true	"Todo object that needs to be created"
+//	@Param			X-Workspace-ID	header	string						false	"Workspace id, the personal workspace when missing"
 //
 //	@Security		ApiKeyAuth
 //	@Success		200	{object}	libs.Response
 //	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
 //	@Failure		500	{object}	libs.Response
 //	@Router			/todos [post]
 func (h *TodosHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -104,41 +172,136 @@ func (h *TodosHandler) Create(w http.ResponseWriter, r *http.Request) {
 	res, err := h.service.Create(r.Context(), todo)
 
 	if err != nil {
-		libs.InternalServerError(w, err.Error())
+		writeTodoError(w, err)
 		return
 	}
 
 	libs.WriteJSON(w, true, http.StatusCreated, "Todo created successfully", res)
 }
 
+// Todos godoc
+//
+//	@Summary		Get a todo
+//	@Description	get a todo by id
+//	@Tags			todos
+//	@Produce		json
+//	@Param			id				path	string	true	"Todo id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id} [get]
+func (h *TodosHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
+	res, err := h.service.GetById(r.Context(), id)
+
+	if err != nil {
+		writeTodoError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Todo retrieved successfully", res)
+}
+
 // Todos godoc
 //
 //	@Summary		Update a todo
-//	@Description	update a todo
+//	@Description	replace the fields of a todo, missing tags, project_id and auto_complete keep their value
 //	@Tags			todos
 //	@Accept			json
 //	@Produce		json
-//	@Param			body	body	types.TodosPutRequestBody	true	"Todo object that needs to be updated"
+//	@Param			id				path	string						true	"Todo id"
+//	@Param			body			body	types.TodosPutRequestBody	true	"Todo object that needs to be updated, the id is ignored"
+//	@Param			X-Workspace-ID	header	string						false	"Workspace id, the personal workspace when missing"
 //	@Security		ApiKeyAuth
 //	@Success		200	{object}	libs.Response
 //	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
 //	@Failure		500	{object}	libs.Response
-//	@Router			/todos [put]
+//	@Router			/todos/{id} [put]
 func (h *TodosHandler) Update(w http.ResponseWriter, r *http.Request) {
-	// handle the request
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
 	var todo types.TodosPutRequestBody
 
-	err := libs.ParseJSON(r, &todo)
+	err = libs.ParseJSON(r, &todo)
 
 	if err != nil {
 		libs.BadRequest(w, "Invalid request body")
 		return
 	}
 
-	res, err := h.service.Update(r.Context(), todo)
+	h.update(w, r, id, todo)
+}
+
+// Todos godoc
+//
+//	@Summary		Patch a todo
+//	@Description	apply a JSON merge patch (RFC 7396), missing fields keep their value and null clears due_date
+//	@Tags			todos
+//	@Accept			json
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			id				path	string						true	"Todo id"
+//	@Param			body			body	types.TodosPatchRequestBody	true	"Fields to change"
+//	@Param			X-Workspace-ID	header	string						false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		415	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/todos/{id} [patch]
+func (h *TodosHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
 
 	if err != nil {
-		libs.InternalServerError(w, err.Error())
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
+	// plain json is read as a merge patch as well
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType != libs.MergePatchContentType && mediaType != "application/json" {
+		libs.WriteJSON(w, false, http.StatusUnsupportedMediaType, "Content type must be "+libs.MergePatchContentType, nil)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	patch, err := libs.ParseMergePatch(body, types.Todos{})
+
+	if err != nil {
+		libs.BadRequest(w, err.Error())
+		return
+	}
+
+	res, err := h.service.Patch(r.Context(), id, patch)
+
+	if err != nil {
+		writeTodoError(w, err)
 		return
 	}
 
@@ -148,17 +311,80 @@ func (h *TodosHandler) Update(w http.ResponseWriter, r *http.Request) {
 // Todos godoc
 //
 //	@Summary		Delete a todo
-//	@Description	delete a todo
+//	@Description	delete a todo by id with its checklist
 //	@Tags			todos
-//	@Accept			json
 //	@Produce		json
-//	@Param			body	body	types.TodosDeleteRequestBody	true	"Todo object that needs to be created"
+//	@Param			id				path	string	true	"Todo id"
+//	@Param			X-Workspace-ID	header	string	false	"Workspace id, the personal workspace when missing"
 //	@Security		ApiKeyAuth
 //	@Success		200	{object}	libs.Response
 //	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
 //	@Failure		500	{object}	libs.Response
-//	@Router			/todos [delete]
+//	@Router			/todos/{id} [delete]
 func (h *TodosHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid todo id")
+		return
+	}
+
+	h.delete(w, r, id)
+}
+
+// Todos godoc
+//
+//	@Summary		Update a todo
+//	@Description	update a todo with the id in the body, use PUT /todos/{id} instead
+//	@Tags			todos
+//	@Accept			json
+//	@Produce		json
+//	@Param			body			body	types.TodosPutRequestBody	true	"Todo object that needs to be updated"
+//	@Param			X-Workspace-ID	header	string						false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Deprecated
+//	@Router	/todos [put]
+func (h *TodosHandler) UpdateByBody(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var todo types.TodosPutRequestBody
+
+	err := libs.ParseJSON(r, &todo)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	setSuccessor(w, r, todo.Id)
+
+	h.update(w, r, todo.Id, todo)
+}
+
+// Todos godoc
+//
+//	@Summary		Delete a todo
+//	@Description	delete a todo with the id in the body, use DELETE /todos/{id} instead
+//	@Tags			todos
+//	@Accept			json
+//	@Produce		json
+//	@Param			body			body	types.TodosDeleteRequestBody	true	"Todo object that needs to be deleted"
+//	@Param			X-Workspace-ID	header	string							false	"Workspace id, the personal workspace when missing"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Deprecated
+//	@Router	/todos [delete]
+func (h *TodosHandler) DeleteByBody(w http.ResponseWriter, r *http.Request) {
 	// handle the request
 	var todo types.TodosDeleteRequestBody
 
@@ -169,16 +395,184 @@ func (h *TodosHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setSuccessor(w, r, todo.Id)
+
+	h.delete(w, r, todo.Id)
+}
+
+func (h *TodosHandler) update(w http.ResponseWriter, r *http.Request, id uuid.UUID, todo types.TodosPutRequestBody) {
+	res, err := h.service.Update(r.Context(), id, todo)
+
+	if err != nil {
+		writeTodoError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Todo updated successfully", res)
+}
+
+func (h *TodosHandler) delete(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
 	// timeout context
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	err = h.service.Delete(ctx, todo)
+	err := h.service.Delete(ctx, id)
 
 	if err != nil {
-		libs.InternalServerError(w, err.Error())
+		writeTodoError(w, err)
 		return
 	}
 
 	libs.WriteJSON(w, true, http.StatusOK, "Todo deleted successfully", nil)
 }
+
+// parseTodosQuery reads the filters, sorting and pagination of the todo list,
+// the offset is left to the search
+func parseTodosQuery(params url.Values) (types.TodosQuery, error) {
+	// set default pagination
+	query := types.TodosQuery{Limit: 10}
+
+	if l, err := strconv.Atoi(params.Get("limit")); err == nil && l > 0 {
+		query.Limit = min(l, maxTodosLimit)
+	}
+
+	query.Cursor = params.Get("cursor")
+
+	if v := params.Get("total"); v != "" {
+		total, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return query, errors.New("invalid total")
+		}
+
+		query.Total = total
+	}
+
+	if v := params.Get("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return query, errors.New("invalid completed filter")
+		}
+
+		query.Completed = &completed
+	}
+
+	if v := params.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+
+		if err != nil {
+			return query, errors.New("invalid overdue filter")
+		}
+
+		query.Overdue = overdue
+	}
+
+	times := []struct {
+		name  string
+		bound **time.Time
+	}{
+		{"due_before", &query.DueBefore},
+		{"due_after", &query.DueAfter},
+		{"created_after", &query.CreatedAfter},
+	}
+
+	for _, t := range times {
+		v := params.Get(t.name)
+
+		if v == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, v)
+
+		if err != nil {
+			return query, errors.New("invalid " + t.name + " time")
+		}
+
+		*t.bound = &parsed
+	}
+
+	query.Q = strings.TrimSpace(params.Get("q"))
+
+	// inbox lists the todos without a project
+	if v := params.Get("project_id"); v == "inbox" {
+		query.Inbox = true
+	} else if v != "" {
+		projectId, err := uuid.Parse(v)
+
+		if err != nil {
+			return query, errors.New("invalid project_id filter")
+		}
+
+		query.ProjectId = &projectId
+	}
+
+	if v := params.Get("tag"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				query.Tags = append(query.Tags, name)
+			}
+		}
+	}
+
+	switch params.Get("tag_match") {
+	case "", "any":
+	case "all":
+		query.TagsMatchAll = true
+	default:
+		return query, errors.New("tag_match must be any or all")
+	}
+
+	if v := params.Get("sort"); v != "" {
+		sort, err := types.ParseTodosSort(v)
+
+		if err != nil {
+			return query, err
+		}
+
+		query.Sort = sort
+	}
+
+	return query, nil
+}
+
+// writeTodosPage writes a page of todos with its cursors in meta and in the Link header
+func writeTodosPage(w http.ResponseWriter, r *http.Request, query types.TodosQuery, res *types.TodosPage) {
+	meta := &libs.Meta{
+		Limit:      query.Limit,
+		NextCursor: res.NextCursor,
+		PrevCursor: res.PrevCursor,
+		Total:      res.Total,
+	}
+
+	w.Header().Set("Link", libs.PageLinks(r.URL, meta))
+
+	libs.WriteJSONWithMeta(w, true, http.StatusOK, "Todos retrieved successfully", res.Todos, meta)
+}
+
+// deprecated marks the responses of a route that is kept for existing clients
+func deprecated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		next(w, r)
+	}
+}
+
+// setSuccessor links a deprecated collection route to the route of the todo
+func setSuccessor(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	w.Header().Set("Link", fmt.Sprintf("<%s/%s>; rel=\"successor-version\"", strings.TrimSuffix(r.URL.Path, "/"), id))
+}
+
+func writeTodoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrTodoNotFound), errors.Is(err, types.ErrWorkspaceNotFound):
+		libs.NotFound(w, err.Error())
+	case errors.Is(err, types.ErrInvalidSearch), errors.Is(err, types.ErrInvalidCursor), errors.Is(err, types.ErrInvalidTagName), errors.Is(err, types.ErrInvalidProject):
+		libs.BadRequest(w, err.Error())
+	case errors.Is(err, types.ErrWorkspaceForbidden):
+		libs.Forbidden(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
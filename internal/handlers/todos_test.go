@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTodosService is a mock implementation of the TodosService
+type MockTodosService struct {
+	mock.Mock
+}
+
+func (m *MockTodosService) Create(ctx context.Context, req types.TodosPostRequestBody) (*types.Todos, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*types.Todos), args.Error(1)
+}
+
+func (m *MockTodosService) Get(ctx context.Context, query types.TodosQuery) (*types.TodosPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(*types.TodosPage), args.Error(1)
+}
+
+func (m *MockTodosService) Search(ctx context.Context, query types.TodosQuery) ([]types.TodoSearchResult, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]types.TodoSearchResult), args.Error(1)
+}
+
+func (m *MockTodosService) GetById(ctx context.Context, id uuid.UUID) (*types.Todos, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*types.Todos), args.Error(1)
+}
+
+func (m *MockTodosService) Update(ctx context.Context, id uuid.UUID, req types.TodosPutRequestBody) (*types.Todos, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*types.Todos), args.Error(1)
+}
+
+func (m *MockTodosService) Patch(ctx context.Context, id uuid.UUID, patch []libs.PatchField) (*types.Todos, error) {
+	args := m.Called(ctx, id, patch)
+	return args.Get(0).(*types.Todos), args.Error(1)
+}
+
+func (m *MockTodosService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+const missingTodoId = "9b1f04c5-7d0e-4a53-9a55-1f0f8e7c1d2a"
+
+// foreignId belongs to a workspace the user is not a member of
+const foreignId = "4e7a2c9b-3f51-4d8e-b6a0-8c2d1e5f7a93"
+
+func TestTodoById(t *testing.T) {
+	mockService := new(MockTodosService)
+	handler := NewTodosHandler(mockService)
+
+	mockService.On("GetById", mock.Anything, uuid.MustParse(UUIDtest)).Return(&types.Todos{Id: uuid.MustParse(UUIDtest)}, nil)
+	mockService.On("GetById", mock.Anything, uuid.MustParse(missingTodoId)).Return((*types.Todos)(nil), types.ErrTodoNotFound)
+	mockService.On("Update", mock.Anything, uuid.MustParse(missingTodoId), mock.Anything).Return((*types.Todos)(nil), types.ErrTodoNotFound)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(UUIDtest)).Return(nil)
+	mockService.On("Delete", mock.Anything, uuid.MustParse(missingTodoId)).Return(types.ErrTodoNotFound)
+	mockService.On("GetById", mock.Anything, uuid.MustParse(foreignId)).Return((*types.Todos)(nil), types.ErrWorkspaceNotFound)
+	mockService.On("Update", mock.Anything, uuid.MustParse(UUIDtest), mock.Anything).Return((*types.Todos)(nil), types.ErrWorkspaceForbidden)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		inputJSON      string
+		expectedStatus int
+		deprecated     bool
+	}{
+		{"Get Todo", http.MethodGet, "/todos/" + UUIDtest, "", http.StatusOK, false},
+		{"Get Missing Todo", http.MethodGet, "/todos/" + missingTodoId, "", http.StatusNotFound, false},
+		{"Invalid Id", http.MethodGet, "/todos/not-a-uuid", "", http.StatusBadRequest, false},
+		{"Update Missing Todo", http.MethodPut, "/todos/" + missingTodoId, `{"title":"title"}`, http.StatusNotFound, false},
+		{"Get Todo Of Another Workspace", http.MethodGet, "/todos/" + foreignId, "", http.StatusNotFound, false},
+		{"Update As Viewer", http.MethodPut, "/todos/" + UUIDtest, `{"title":"title"}`, http.StatusForbidden, false},
+		{"Delete Todo", http.MethodDelete, "/todos/" + UUIDtest, "", http.StatusOK, false},
+		{"Delete Missing Todo", http.MethodDelete, "/todos/" + missingTodoId, "", http.StatusNotFound, false},
+		{"Delete By Body", http.MethodDelete, "/todos/", `{"id":"` + UUIDtest + `"}`, http.StatusOK, true},
+		{"Delete Missing By Body", http.MethodDelete, "/todos/", `{"id":"` + missingTodoId + `"}`, http.StatusNotFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.inputJSON))
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/todos", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.deprecated {
+				assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+				assert.Contains(t, rr.Header().Get("Link"), `rel="successor-version"`)
+			} else {
+				assert.Empty(t, rr.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func TestPatchTodo(t *testing.T) {
+	mockService := new(MockTodosService)
+	handler := NewTodosHandler(mockService)
+
+	mockService.On("Patch", mock.Anything, uuid.MustParse(UUIDtest), []libs.PatchField{{Column: "completed", Value: true}, {Column: "due_date"}}).
+		Return(&types.Todos{Id: uuid.MustParse(UUIDtest), Title: "kept", Completed: true}, nil)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		inputJSON      string
+		expectedStatus int
+	}{
+		{"Merge Patch", libs.MergePatchContentType, `{"due_date":null,"completed":true}`, http.StatusOK},
+		{"Plain JSON", "application/json; charset=utf-8", `{"completed":true,"due_date":null}`, http.StatusOK},
+		{"Unsupported Content Type", "text/plain", `{"completed":true}`, http.StatusUnsupportedMediaType},
+		{"Read Only Field", libs.MergePatchContentType, `{"id":"` + UUIDtest + `"}`, http.StatusBadRequest},
+		{"Unknown Field", libs.MergePatchContentType, `{"priority":1}`, http.StatusBadRequest},
+		{"Null Title", libs.MergePatchContentType, `{"title":null}`, http.StatusBadRequest},
+		{"Progress Is Read Only", libs.MergePatchContentType, `{"progress":{"done":1,"total":1}}`, http.StatusBadRequest},
+		{"Not An Object", libs.MergePatchContentType, `[]`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPatch, "/todos/"+UUIDtest, bytes.NewBufferString(tt.inputJSON))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/todos", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestGetTodosQuery(t *testing.T) {
+	mockService := new(MockTodosService)
+	handler := NewTodosHandler(mockService)
+
+	completed := false
+	dueBefore := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	total := 42
+
+	mockService.On("Get", mock.Anything, types.TodosQuery{Limit: 10}).Return(&types.TodosPage{Todos: []types.Todos{}, NextCursor: "next"}, nil)
+	mockService.On("Get", mock.Anything, types.TodosQuery{
+		Completed: &completed,
+		DueBefore: &dueBefore,
+		Overdue:   true,
+		Q:         "milk",
+		Sort:      []types.TodosSort{{Column: "due_date"}, {Column: "created_at", Desc: true}},
+		Limit:     100,
+		Cursor:    "abc",
+		Total:     true,
+	}).Return(&types.TodosPage{Todos: []types.Todos{}, PrevCursor: "prev", Total: &total}, nil)
+	mockService.On("Get", mock.Anything, types.TodosQuery{Limit: 10, Cursor: "stale"}).Return((*types.TodosPage)(nil), types.ErrInvalidCursor)
+	mockService.On("Get", mock.Anything, types.TodosQuery{Inbox: true, Limit: 10}).Return(&types.TodosPage{Todos: []types.Todos{}}, nil)
+	mockService.On("Get", mock.Anything, types.TodosQuery{Tags: []string{"work", "home"}, TagsMatchAll: true, Limit: 10}).Return(&types.TodosPage{Todos: []types.Todos{}}, nil)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedLink   string
+	}{
+		{"Defaults", "", http.StatusOK, `</todos/>; rel="first", </todos/?cursor=next>; rel="next"`},
+		{"Filters", "?completed=false&due_before=2026-11-01T00:00:00Z&overdue=true&q=+milk+&sort=due_date,-created_at&limit=500&cursor=abc&total=true", http.StatusOK, ""},
+		{"Invalid Cursor", "?cursor=stale", http.StatusBadRequest, ""},
+		{"Invalid Completed", "?completed=maybe", http.StatusBadRequest, ""},
+		{"Invalid Time", "?due_after=tomorrow", http.StatusBadRequest, ""},
+		{"Invalid Sort", "?sort=password", http.StatusBadRequest, ""},
+		{"Tags", "?tag=work,+home,&tag_match=all", http.StatusOK, ""},
+		{"Inbox", "?project_id=inbox", http.StatusOK, ""},
+		{"Invalid Project", "?project_id=groceries", http.StatusBadRequest, ""},
+		{"Invalid Tag Match", "?tag=work&tag_match=none", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/todos/"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/todos", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedLink != "" {
+				assert.Equal(t, tt.expectedLink, rr.Header().Get("Link"))
+			}
+		})
+	}
+}
+
+func TestSearchTodos(t *testing.T) {
+	mockService := new(MockTodosService)
+	handler := NewTodosHandler(mockService)
+
+	completed := true
+
+	mockService.On("Search", mock.Anything, types.TodosQuery{Q: "milk", Completed: &completed, Limit: 10}).Return([]types.TodoSearchResult{}, nil)
+	mockService.On("Search", mock.Anything, types.TodosQuery{Limit: 10}).Return([]types.TodoSearchResult(nil), types.ErrInvalidSearch)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{"Search", "?q=milk&completed=true", http.StatusOK},
+		{"Missing Query", "", http.StatusBadRequest},
+		{"Invalid Filter", "?q=milk&created_after=yesterday", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/todos/search"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Route("/todos", handler.RegisterRoute)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type TokensHandler struct {
+	service types.TokensServices
+}
+
+func NewTokensHandler(service types.TokensServices) *TokensHandler {
+	return &TokensHandler{service: service}
+}
+
+func (h *TokensHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/", h.Get)
+	r.Post("/", h.Create)
+	r.Get("/{id}", h.GetById)
+	r.Patch("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+}
+
+// Tokens godoc
+//
+//	@Summary		Create a personal access token
+//	@Description	create a personal access token, the token is only returned once
+//	@Tags			tokens
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.PersonalAccessTokenPostRequestBody	true	"Token name, scopes and expiry"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/tokens [post]
+func (h *TokensHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.PersonalAccessTokenPostRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil || strings.TrimSpace(req.Name) == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Create(r.Context(), req)
+
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusCreated, "Token created successfully", res)
+}
+
+// Tokens godoc
+//
+//	@Summary		Get personal access tokens
+//	@Description	list the personal access tokens of the current user
+//	@Tags			tokens
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/tokens [get]
+func (h *TokensHandler) Get(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Get(r.Context())
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Tokens retrieved successfully", res)
+}
+
+// Tokens godoc
+//
+//	@Summary		Get a personal access token
+//	@Description	get a personal access token by id
+//	@Tags			tokens
+//	@Produce		json
+//	@Param			id	path	string	true	"Token id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/tokens/{id} [get]
+func (h *TokensHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid token id")
+		return
+	}
+
+	res, err := h.service.GetById(r.Context(), id)
+
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Token retrieved successfully", res)
+}
+
+// Tokens godoc
+//
+//	@Summary		Update a personal access token
+//	@Description	rename a personal access token or change its scopes
+//	@Tags			tokens
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string										true	"Token id"
+//	@Param			body	body	types.PersonalAccessTokenPatchRequestBody	true	"Fields to update"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/tokens/{id} [patch]
+func (h *TokensHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid token id")
+		return
+	}
+
+	var req types.PersonalAccessTokenPatchRequestBody
+
+	err = libs.ParseJSON(r, &req)
+
+	if err != nil || (req.Name != nil && strings.TrimSpace(*req.Name) == "") {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Update(r.Context(), id, req)
+
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Token updated successfully", res)
+}
+
+// Tokens godoc
+//
+//	@Summary		Delete a personal access token
+//	@Description	revoke a personal access token
+//	@Tags			tokens
+//	@Produce		json
+//	@Param			id	path	string	true	"Token id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/auth/tokens/{id} [delete]
+func (h *TokensHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid token id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Token deleted successfully", nil)
+}
+
+func writeTokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrTokenNotFound):
+		libs.NotFound(w, err.Error())
+	case errors.Is(err, types.ErrInvalidScope):
+		libs.BadRequest(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type WorkspacesHandler struct {
+	service types.WorkspacesServices
+}
+
+func NewWorkspacesHandler(service types.WorkspacesServices) *WorkspacesHandler {
+	return &WorkspacesHandler{service: service}
+}
+
+func (h *WorkspacesHandler) RegisterRoute(r chi.Router) {
+	// handle the request
+	r.Get("/", h.Get)
+	r.Post("/", h.Create)
+	r.Delete("/{id}", h.Delete)
+	r.Get("/{id}/members", h.GetMembers)
+	r.Post("/{id}/members", h.AddMember)
+	r.Patch("/{id}/members/{userId}", h.UpdateMember)
+	r.Delete("/{id}/members/{userId}", h.RemoveMember)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Get workspaces
+//	@Description	list the workspaces the current user is a member of, the personal workspace first
+//	@Tags			workspaces
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces [get]
+func (h *WorkspacesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Get(r.Context())
+
+	if err != nil {
+		libs.InternalServerError(w, err.Error())
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Workspaces retrieved successfully", res)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Create a workspace
+//	@Description	create a shared workspace owned by the current user
+//	@Tags			workspaces
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	types.WorkspacePostRequestBody	true	"Workspace name"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces [post]
+func (h *WorkspacesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// handle the request
+	var req types.WorkspacePostRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.Create(r.Context(), req)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusCreated, "Workspace created successfully", res)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Delete a workspace
+//	@Description	delete a shared workspace with its todos, owners only
+//	@Tags			workspaces
+//	@Produce		json
+//	@Param			id	path	string	true	"Workspace id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces/{id} [delete]
+func (h *WorkspacesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid workspace id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Workspace deleted successfully", nil)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Get workspace members
+//	@Description	list the members of a workspace and their roles
+//	@Tags			workspaces
+//	@Produce		json
+//	@Param			id	path	string	true	"Workspace id"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces/{id}/members [get]
+func (h *WorkspacesHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid workspace id")
+		return
+	}
+
+	res, err := h.service.GetMembers(r.Context(), id)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Members retrieved successfully", res)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Add a workspace member
+//	@Description	add a registered user to a shared workspace, admins and owners only. Only owners can add owners
+//	@Tags			workspaces
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string									true	"Workspace id"
+//	@Param			body	body	types.WorkspaceMemberPostRequestBody	true	"Email of the user and role, member by default"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		409	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces/{id}/members [post]
+func (h *WorkspacesHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid workspace id")
+		return
+	}
+
+	var req types.WorkspaceMemberPostRequestBody
+
+	err = libs.ParseJSON(r, &req)
+
+	if err != nil || req.Email == "" {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.AddMember(r.Context(), id, req)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusCreated, "Member added successfully", res)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Change a member's role
+//	@Description	change the role of a workspace member, admins and owners only. Only owners can grant or take away the owner role and the last owner keeps it
+//	@Tags			workspaces
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string									true	"Workspace id"
+//	@Param			userId	path	string									true	"User id of the member"
+//	@Param			body	body	types.WorkspaceMemberPatchRequestBody	true	"New role"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		409	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces/{id}/members/{userId} [patch]
+func (h *WorkspacesHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	id, userId, ok := workspaceMemberParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	var req types.WorkspaceMemberPatchRequestBody
+
+	err := libs.ParseJSON(r, &req)
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	res, err := h.service.UpdateMember(r.Context(), id, userId, req)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Member updated successfully", res)
+}
+
+// Workspaces godoc
+//
+//	@Summary		Remove a workspace member
+//	@Description	remove a member from a workspace, members can always remove themselves. The last owner can not leave
+//	@Tags			workspaces
+//	@Produce		json
+//	@Param			id		path	string	true	"Workspace id"
+//	@Param			userId	path	string	true	"User id of the member"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	libs.Response
+//	@Failure		400	{object}	libs.Response
+//	@Failure		403	{object}	libs.Response
+//	@Failure		404	{object}	libs.Response
+//	@Failure		409	{object}	libs.Response
+//	@Failure		500	{object}	libs.Response
+//	@Router			/workspaces/{id}/members/{userId} [delete]
+func (h *WorkspacesHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, userId, ok := workspaceMemberParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := h.service.RemoveMember(r.Context(), id, userId)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	libs.WriteJSON(w, true, http.StatusOK, "Member removed successfully", nil)
+}
+
+// workspaceMemberParams parses the workspace and member ids of the path,
+// writing a bad request when either is invalid
+func workspaceMemberParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid workspace id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "userId"))
+
+	if err != nil {
+		libs.BadRequest(w, "Invalid user id")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userId, true
+}
+
+func writeWorkspaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrWorkspaceNotFound), errors.Is(err, types.ErrMemberNotFound), errors.Is(err, types.ErrUserNotFound):
+		libs.NotFound(w, err.Error())
+	case errors.Is(err, types.ErrWorkspaceForbidden):
+		libs.Forbidden(w, err.Error())
+	case errors.Is(err, types.ErrMemberExists), errors.Is(err, types.ErrLastOwner):
+		libs.WriteJSON(w, false, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, types.ErrInvalidWorkspaceName), errors.Is(err, types.ErrInvalidWorkspaceRole), errors.Is(err, types.ErrPersonalWorkspace):
+		libs.BadRequest(w, err.Error())
+	default:
+		libs.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,60 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Lockout counts failed attempts per key and locks a key out once it fails too often
+type Lockout interface {
+	// LockedFor returns how long the key stays locked, zero if it is not locked
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt, it returns the failures within the policy window
+	// and the lockout the attempt triggered, zero if there is none
+	Fail(ctx context.Context, key string, policy Policy) (int, time.Duration, error)
+	// Reset forgets the failures and any lockout of the key
+	Reset(ctx context.Context, key string) error
+}
+
+type Policy struct {
+	// failures allowed within the window before the key is locked
+	MaxFailures int
+	Window      time.Duration
+	// the first lockout, doubled by every further failure up to MaxLockout
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// LockoutFor returns the lockout earned by the given number of failures
+func (p Policy) LockoutFor(failures int) time.Duration {
+	if p.MaxFailures <= 0 || failures < p.MaxFailures {
+		return 0
+	}
+
+	lockout := p.BaseLockout
+
+	for i := p.MaxFailures; i < failures && lockout < p.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if lockout > p.MaxLockout {
+		return p.MaxLockout
+	}
+
+	return lockout
+}
+
+// retention keeps the failure count around for as long as a lockout lasts,
+// so the next failure after a lockout escalates instead of starting over
+func (p Policy) retention(lockout time.Duration) time.Duration {
+	return p.Window + lockout
+}
+
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func IpKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,74 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyLockoutFor(t *testing.T) {
+	policy := Policy{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  5 * time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		expected time.Duration
+	}{
+		{name: "below the threshold", failures: 2, expected: 0},
+		{name: "at the threshold", failures: 3, expected: 30 * time.Second},
+		{name: "doubles", failures: 4, expected: time.Minute},
+		{name: "doubles again", failures: 5, expected: 2 * time.Minute},
+		{name: "capped", failures: 100, expected: 5 * time.Minute},
+		{name: "disabled", failures: 100, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+
+			if tt.name == "disabled" {
+				p.MaxFailures = 0
+			}
+
+			assert.Equal(t, tt.expected, p.LockoutFor(tt.failures))
+		})
+	}
+}
+
+func TestMemoryLockout(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryLockout()
+	policy := Policy{MaxFailures: 2, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+	key := AccountKey(" User@Example.com ")
+
+	failures, lockout, _ := l.Fail(ctx, key, policy)
+	assert.Equal(t, 1, failures)
+	assert.Zero(t, lockout)
+
+	lockedFor, _ := l.LockedFor(ctx, key)
+	assert.Zero(t, lockedFor)
+
+	failures, lockout, _ = l.Fail(ctx, key, policy)
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, time.Minute, lockout)
+
+	// keys are case insensitive
+	lockedFor, _ = l.LockedFor(ctx, AccountKey("user@example.com"))
+	assert.Greater(t, lockedFor, time.Duration(0))
+
+	// other keys are unaffected
+	lockedFor, _ = l.LockedFor(ctx, IpKey("127.0.0.1"))
+	assert.Zero(t, lockedFor)
+
+	l.Reset(ctx, key)
+
+	lockedFor, _ = l.LockedFor(ctx, key)
+	assert.Zero(t, lockedFor)
+}
@@ -0,0 +1,86 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type attempts struct {
+	failures    int
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+type MemoryLockout struct {
+	sync.Mutex
+	keys map[string]attempts
+}
+
+// NewMemoryLockout keeps failed attempts in process memory
+func NewMemoryLockout() *MemoryLockout {
+	return &MemoryLockout{
+		keys: make(map[string]attempts),
+	}
+}
+
+func (l *MemoryLockout) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	entry, exist := l.keys[key]
+
+	if !exist {
+		return 0, nil
+	}
+
+	lockedFor := time.Until(entry.lockedUntil)
+
+	if lockedFor < 0 {
+		return 0, nil
+	}
+
+	return lockedFor, nil
+}
+
+func (l *MemoryLockout) Fail(ctx context.Context, key string, policy Policy) (int, time.Duration, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.prune()
+
+	now := time.Now()
+	entry := l.keys[key]
+	entry.failures++
+
+	lockout := policy.LockoutFor(entry.failures)
+
+	if lockout > 0 {
+		entry.lockedUntil = now.Add(lockout)
+	}
+
+	entry.expiresAt = now.Add(policy.retention(lockout))
+	l.keys[key] = entry
+
+	return entry.failures, lockout, nil
+}
+
+func (l *MemoryLockout) Reset(ctx context.Context, key string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.keys, key)
+
+	return nil
+}
+
+// prune drops keys whose failures are forgotten, caller must hold the lock
+func (l *MemoryLockout) prune() {
+	now := time.Now()
+
+	for key, entry := range l.keys {
+		if now.After(entry.expiresAt) {
+			delete(l.keys, key)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RedisLockout shares failed attempts between instances through redis,
+// falling back to process memory while redis is unreachable
+type RedisLockout struct {
+	client   *redis.Client
+	fallback *MemoryLockout
+}
+
+func NewRedisLockout(client *redis.Client) *RedisLockout {
+	return &RedisLockout{
+		client:   client,
+		fallback: NewMemoryLockout(),
+	}
+}
+
+func failuresKey(key string) string {
+	return "lockout:failures:" + key
+}
+
+func lockedKey(key string) string {
+	return "lockout:locked:" + key
+}
+
+func (l *RedisLockout) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := l.client.PTTL(ctx, lockedKey(key)).Result()
+
+	if err != nil {
+		zap.L().Warn("lockout: redis unavailable, using in-memory fallback", zap.Error(err))
+		return l.fallback.LockedFor(ctx, key)
+	}
+
+	// missing keys report a negative ttl
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (l *RedisLockout) Fail(ctx context.Context, key string, policy Policy) (int, time.Duration, error) {
+	failures, err := l.client.Incr(ctx, failuresKey(key)).Result()
+
+	if err != nil {
+		zap.L().Warn("lockout: redis unavailable, using in-memory fallback", zap.Error(err))
+		return l.fallback.Fail(ctx, key, policy)
+	}
+
+	lockout := policy.LockoutFor(int(failures))
+
+	_, err = l.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Expire(ctx, failuresKey(key), policy.retention(lockout))
+
+		if lockout > 0 {
+			pipe.Set(ctx, lockedKey(key), failures, lockout)
+		}
+
+		return nil
+	})
+
+	return int(failures), lockout, err
+}
+
+func (l *RedisLockout) Reset(ctx context.Context, key string) error {
+	l.fallback.Reset(ctx, key)
+
+	err := l.client.Del(ctx, failuresKey(key), lockedKey(key)).Err()
+
+	if err != nil {
+		zap.L().Warn("lockout: redis unavailable, key reset in memory only", zap.Error(err))
+	}
+
+	return nil
+}
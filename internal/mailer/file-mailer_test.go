@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailerSend(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir, "no-reply@todoapp.local")
+
+	err := m.Send(context.Background(), Message{
+		To:      "test@example.com",
+		Subject: "Verify your email address",
+		Body:    "hello",
+	})
+
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Len(t, files, 1)
+
+	content, _ := os.ReadFile(files[0])
+	assert.Contains(t, string(content), "To: test@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Verify your email address\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nhello\r\n")
+}
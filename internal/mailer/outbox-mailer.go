@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// OutboxMailer keeps every message in memory instead of sending it, meant
+// for tests
+type OutboxMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewOutboxMailer() *OutboxMailer {
+	return &OutboxMailer{}
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (m *OutboxMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxMailerSend(t *testing.T) {
+	m := NewOutboxMailer()
+
+	assert.Empty(t, m.Messages())
+
+	for _, to := range []string{"first@example.com", "second@example.com"} {
+		err := m.Send(context.Background(), Message{To: to, Subject: "Sign in to TodoApp", Body: "hello"})
+		assert.NoError(t, err)
+	}
+
+	messages := m.Messages()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "first@example.com", messages[0].To)
+	assert.Equal(t, "second@example.com", messages[1].To)
+
+	// the returned slice is a copy
+	messages[0].To = "changed@example.com"
+	assert.Equal(t, "first@example.com", m.Messages()[0].To)
+}
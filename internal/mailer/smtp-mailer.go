@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+)
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+
+	// relays without authentication are allowed for local setups
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.host, m.port), auth, m.from, []string{msg.To}, buildMessage(m.from, msg))
+}
@@ -0,0 +1,94 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// rsa
+	N string `json:"n"`
+	E string `json:"e"`
+	// ec
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// publicKeys decodes the signing keys of the set by key id, unsupported keys are skipped
+func (s jwks) publicKeys() (map[string]any, error) {
+	keys := make(map[string]any)
+
+	for _, k := range s.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+
+			if err != nil {
+				return nil, err
+			}
+
+			e, err := decodeBigInt(k.E)
+
+			if err != nil {
+				return nil, err
+			}
+
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+
+			x, err := decodeBigInt(k.X)
+
+			if err != nil {
+				return nil, err
+			}
+
+			y, err := decodeBigInt(k.Y)
+
+			if err != nil {
+				return nil, err
+			}
+
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid jwk: %w", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,325 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/odev-swe/todoapp/configs"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrInvalidNonce   = errors.New("id token nonce does not match")
+)
+
+// jwksRefreshInterval throttles the key set refetches caused by unknown key
+// ids, the ids come from unverified tokens
+const jwksRefreshInterval = time.Minute
+
+// Discovery holds the parts of the provider metadata the code flow needs
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Nonce         string   `json:"nonce"`
+}
+
+// Provider runs the authorization code flow with PKCE against one identity provider.
+// Discovery and keys are fetched on first use and cached.
+type Provider struct {
+	sync.Mutex
+	config    configs.OidcProvider
+	client    *http.Client
+	discovery *Discovery
+	keys      map[string]any
+	// when the key set was last fetched, or attempted
+	keysFetchedAt time.Time
+}
+
+func NewProvider(config configs.OidcProvider, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Provider{
+		config: config,
+		client: client,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// Discover fetches the discovery document of the issuer
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery Discovery
+
+	err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a document for another issuer would let that issuer mint our logins
+	if discovery.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", discovery.Issuer, p.config.Issuer)
+	}
+
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+// AuthCodeURL builds the url the user agent is redirected to
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := p.Discover(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectUrl)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (*TokenResponse, error) {
+	discovery, err := p.Discover(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectUrl)
+	form.Set("client_id", p.config.ClientId)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token endpoint returned %s", res.Status)
+	}
+
+	var token TokenResponse
+
+	err = json.NewDecoder(res.Body).Decode(&token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IdToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an id token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIdToken string, nonce string) (*IDTokenClaims, error) {
+	discovery, err := p.Discover(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &IDTokenClaims{}
+
+	_, err = jwt.ParseWithClaims(rawIdToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+
+	return claims, nil
+}
+
+// key returns the verification key with the given id, the key set is
+// refetched when the id is unknown to follow provider key rotation, at most
+// once per jwksRefreshInterval
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.Lock()
+	key, ok := lookupKey(p.keys, kid)
+
+	if !ok && time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		p.Unlock()
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidIDToken, kid)
+	}
+
+	// a failed fetch counts too so an unreachable provider is not hammered
+	if !ok {
+		p.keysFetchedAt = time.Now()
+	}
+	p.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	discovery, err := p.Discover(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwks
+
+	err = p.getJSON(ctx, discovery.JwksUri, &set)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := set.publicKeys()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	p.keys = keys
+	p.Unlock()
+
+	key, ok = lookupKey(keys, kid)
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidIDToken, kid)
+	}
+
+	return key, nil
+}
+
+// lookupKey finds a key by id, providers with a single key may omit the kid
+func lookupKey(keys map[string]any, kid string) (any, bool) {
+	if key, ok := keys[kid]; ok {
+		return key, true
+	}
+
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s returned %s", url, res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// flexBool accepts both true and "true", some providers send email_verified as a string
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+
+	*b = flexBool(value == "true")
+
+	return nil
+}
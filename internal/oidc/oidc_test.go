@@ -0,0 +1,258 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/odev-swe/todoapp/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdP is an in-process identity provider issuing codes for a fixed user
+type mockIdP struct {
+	sync.Mutex
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// code -> pending authorization
+	codes    map[string]authorization
+	audience string
+	// how often the key set was fetched
+	jwksFetches int
+}
+
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIdP{key: key, codes: make(map[string]authorization), audience: "todoapp"}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JwksUri:               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.Lock()
+		idp.jwksFetches++
+		idp.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	// the user consents right away and is sent back with a code
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if query.Get("code_challenge_method") != "S256" {
+			http.Error(w, "pkce required", http.StatusBadRequest)
+			return
+		}
+
+		idp.Lock()
+		idp.codes["code-123"] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+		idp.Unlock()
+
+		http.Redirect(w, r, query.Get("redirect_uri")+"?code=code-123&state="+url.QueryEscape(query.Get("state")), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		idp.Lock()
+		auth, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.Unlock()
+
+		if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != auth.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken: "idp-access-token",
+			TokenType:   "Bearer",
+			IdToken:     idp.idToken(t, auth.nonce),
+			ExpiresIn:   3600,
+		})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *mockIdP) idToken(t *testing.T, nonce string) string {
+	return idp.idTokenWithKid(t, nonce, "test-key")
+}
+
+func (idp *mockIdP) idTokenWithKid(t *testing.T, nonce string, kid string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "external-user-1",
+		"aud":            idp.audience,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"email":          "test@example.com",
+		"email_verified": true,
+		"nonce":          nonce,
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(idp.key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func newTestProvider(idp *mockIdP) *Provider {
+	return NewProvider(configs.OidcProvider{
+		Name:        "mock",
+		Issuer:      idp.server.URL,
+		ClientId:    "todoapp",
+		RedirectUrl: "http://localhost:3000/api/v1/auth/oidc/mock/callback",
+		Scopes:      []string{"openid", "email"},
+	}, nil)
+}
+
+// authorize follows the authorization url like a browser would and returns the callback query
+func authorize(t *testing.T, authUrl string) url.Values {
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	res, err := client.Get(authUrl)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+
+	return location.Query()
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	verifier, challenge, err := NewPKCE()
+	require.NoError(t, err)
+
+	authUrl, err := provider.AuthCodeURL(ctx, "state-abc", "nonce-xyz", challenge)
+	require.NoError(t, err)
+
+	callback := authorize(t, authUrl)
+	assert.Equal(t, "state-abc", callback.Get("state"))
+
+	token, err := provider.Exchange(ctx, callback.Get("code"), verifier)
+	require.NoError(t, err)
+
+	claims, err := provider.VerifyIDToken(ctx, token.IdToken, "nonce-xyz")
+	require.NoError(t, err)
+
+	assert.Equal(t, "external-user-1", claims.Subject)
+	assert.Equal(t, "test@example.com", claims.Email)
+	assert.True(t, bool(claims.EmailVerified))
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	_, challenge, err := NewPKCE()
+	require.NoError(t, err)
+
+	authUrl, err := provider.AuthCodeURL(ctx, "state-abc", "nonce-xyz", challenge)
+	require.NoError(t, err)
+
+	callback := authorize(t, authUrl)
+
+	_, err = provider.Exchange(ctx, callback.Get("code"), "not-the-verifier")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	t.Run("Nonce Mismatch", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(ctx, idp.idToken(t, "other-nonce"), "nonce-xyz")
+		assert.ErrorIs(t, err, ErrInvalidNonce)
+	})
+
+	t.Run("Wrong Audience", func(t *testing.T) {
+		idp.audience = "another-client"
+		defer func() { idp.audience = "todoapp" }()
+
+		_, err := provider.VerifyIDToken(ctx, idp.idToken(t, "nonce-xyz"), "nonce-xyz")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("Unsigned Token", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"iss": idp.server.URL, "aud": "todoapp", "sub": "x", "nonce": "nonce-xyz"})
+		raw, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+		_, err := provider.VerifyIDToken(ctx, raw, "nonce-xyz")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestUnknownKeyIdRefetchThrottle(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	_, err := provider.VerifyIDToken(ctx, idp.idToken(t, "nonce-xyz"), "nonce-xyz")
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = provider.VerifyIDToken(ctx, idp.idTokenWithKid(t, "nonce-xyz", fmt.Sprintf("unknown-%d", i)), "nonce-xyz")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	}
+
+	assert.Equal(t, 1, idp.jwksFetches)
+
+	// the window passed, a rotated key is looked up again
+	provider.keysFetchedAt = time.Now().Add(-jwksRefreshInterval)
+
+	_, err = provider.VerifyIDToken(ctx, idp.idTokenWithKid(t, "nonce-xyz", "rotated"), "nonce-xyz")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+	assert.Equal(t, 2, idp.jwksFetches)
+}
@@ -0,0 +1,25 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// NewPKCE returns an RFC 7636 code verifier and its S256 challenge
+func NewPKCE() (string, string, error) {
+	verifier, err := libs.RandomToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return verifier, CodeChallenge(verifier), nil
+}
+
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,66 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RedisLimiter counts requests per key in redis, so the limit is shared
+// between instances and nothing is kept in process memory per key
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisLimiter allows limit requests per key within window, keys are
+// stored under prefix
+func NewRedisLimiter(client *redis.Client, prefix string, limit int, window time.Duration) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Allow counts a request of key, it returns whether it is allowed and how
+// long until the window of the key ends. Requests are refused while redis is
+// unreachable.
+func (rl *RedisLimiter) Allow(key string) (bool, time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	key = "ratelimit:" + rl.prefix + ":" + key
+
+	count, err := rl.client.Incr(ctx, key).Result()
+
+	if err != nil {
+		zap.L().Warn("ratelimiter: redis unavailable, refusing request", zap.Error(err))
+		return false, rl.window
+	}
+
+	ttl, err := rl.client.PTTL(ctx, key).Result()
+
+	if err != nil {
+		zap.L().Warn("ratelimiter: redis unavailable, refusing request", zap.Error(err))
+		return false, rl.window
+	}
+
+	// the window starts with the first request, a key left without expiry
+	// by a failed call gets one as well
+	if ttl < 0 {
+		ttl = rl.window
+
+		if err = rl.client.PExpire(ctx, key, rl.window).Err(); err != nil {
+			zap.L().Warn("ratelimiter: redis unavailable, refusing request", zap.Error(err))
+			return false, rl.window
+		}
+	}
+
+	return count <= int64(rl.limit), ttl
+}
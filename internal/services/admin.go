@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/configs"
+	"github.com/odev-swe/todoapp/internal/lockout"
+	"github.com/odev-swe/todoapp/internal/mailer"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+	"go.uber.org/zap"
+)
+
+type AdminService struct {
+	authStore *store.AuthStore
+	users     *store.UsersStore
+	lockout   lockout.Lockout
+	mailer    mailer.Mailer
+	audit     *store.AuditStore
+	config    *configs.Config
+}
+
+func NewAdminService(authStore *store.AuthStore, users *store.UsersStore, lockout lockout.Lockout, mailer mailer.Mailer, audit *store.AuditStore, config *configs.Config) *AdminService {
+	return &AdminService{
+		authStore: authStore,
+		users:     users,
+		lockout:   lockout,
+		mailer:    mailer,
+		audit:     audit,
+		config:    config,
+	}
+}
+
+func (s *AdminService) Unlock(ctx context.Context, req types.UnlockRequestBody) error {
+	var keys []string
+
+	if req.Email != "" {
+		keys = append(keys, lockout.AccountKey(req.Email))
+	}
+
+	if req.Ip != "" {
+		keys = append(keys, lockout.IpKey(req.Ip))
+	}
+
+	for _, key := range keys {
+		err := s.lockout.Reset(ctx, key)
+
+		if err != nil {
+			return err
+		}
+
+		zap.L().Info("Login unlocked", zap.String("key", key))
+	}
+
+	recordAudit(ctx, s.audit, types.AuditLoginUnlocked, currentUser(ctx), map[string]string{"email": req.Email, "ip": req.Ip})
+
+	return nil
+}
+
+func (s *AdminService) GetUsers(ctx context.Context, query types.UsersQuery) ([]types.User, error) {
+	return s.users.Get(ctx, query)
+}
+
+func (s *AdminService) DisableUser(ctx context.Context, id uuid.UUID) error {
+	err := s.authStore.DisableUser(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("User disabled", zap.String("user", id.String()))
+
+	recordAudit(ctx, s.audit, types.AuditUserDisabled, currentUser(ctx), map[string]string{"user_id": id.String()})
+
+	return nil
+}
+
+func (s *AdminService) EnableUser(ctx context.Context, id uuid.UUID) error {
+	err := s.authStore.EnableUser(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditUserEnabled, currentUser(ctx), map[string]string{"user_id": id.String()})
+
+	return nil
+}
+
+func (s *AdminService) ForcePasswordReset(ctx context.Context, id uuid.UUID) error {
+	user, token, err := s.authStore.ForcePasswordReset(ctx, id, time.Duration(s.config.PasswordResetTTL)*time.Second)
+
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("Password reset forced", zap.String("user", id.String()))
+
+	recordAudit(ctx, s.audit, types.AuditPasswordResetForced, currentUser(ctx), map[string]string{"user_id": id.String()})
+
+	// the password is already cleared, a failed email can be followed by a forgot password request
+	err = sendPasswordReset(ctx, s.mailer, s.config, user, token, "An administrator has reset the password of your TodoApp account, your previous password no longer works.", "")
+
+	if err != nil {
+		zap.L().Error("Error sending password reset email", zap.String("user", id.String()), zap.Error(err))
+	}
+
+	return nil
+}
+
+func (s *AdminService) SetRole(ctx context.Context, id uuid.UUID, req types.RoleRequestBody) error {
+	if !slices.Contains(types.Roles, req.Role) {
+		return types.ErrInvalidRole
+	}
+
+	err := s.authStore.SetRole(ctx, id, req.Role)
+
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("User role changed", zap.String("user", id.String()), zap.String("role", req.Role))
+
+	recordAudit(ctx, s.audit, types.AuditRoleChanged, currentUser(ctx), map[string]string{"user_id": id.String(), "role": req.Role})
+
+	return nil
+}
+
+func (s *AdminService) GetAuditEvents(ctx context.Context, query types.AuditQuery) ([]types.AuditEvent, error) {
+	return s.audit.Get(ctx, query)
+}
+
+func (s *AdminService) VerifyAuditLog(ctx context.Context) (*types.AuditVerification, error) {
+	return s.audit.Verify(ctx)
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+	"go.uber.org/zap"
+)
+
+// recordAudit appends a security event to the audit log, a failure is logged
+// and never fails the request
+func recordAudit(ctx context.Context, audit *store.AuditStore, eventType string, actorId *uuid.UUID, metadata map[string]string) {
+	// the event is recorded even when the client already went away
+	err := audit.Record(context.WithoutCancel(ctx), eventType, actorId, metadata)
+
+	if err != nil {
+		zap.L().Error("Error recording audit event", zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// currentUser returns the authenticated user of the request, nil for anonymous requests
+func currentUser(ctx context.Context) *uuid.UUID {
+	userId, _ := ctx.Value(types.UserIdKey("user-id")).(string)
+	id, err := uuid.Parse(userId)
+
+	if err != nil {
+		return nil
+	}
+
+	return &id
+}
+
+// recordLogin records a completed first factor, users with 2fa still need to
+// pass the challenge. metadata names the login method.
+func recordLogin(ctx context.Context, audit *store.AuditStore, user *types.User, metadata map[string]string) {
+	eventType := types.AuditLoginSucceeded
+
+	if user.Token.MfaToken != "" {
+		eventType = types.AuditLoginMfaRequired
+	}
+
+	recordAudit(ctx, audit, eventType, &user.Id, metadata)
+}
@@ -2,23 +2,492 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/configs"
+	"github.com/odev-swe/todoapp/internal/lockout"
+	"github.com/odev-swe/todoapp/internal/mailer"
+	"github.com/odev-swe/todoapp/internal/ratelimiter"
 	"github.com/odev-swe/todoapp/internal/store"
 	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+	"go.uber.org/zap"
 )
 
 type AuthService struct {
-	store *store.AuthStore
+	store        *store.AuthStore
+	mailer       mailer.Mailer
+	config       *configs.Config
+	resetLimiter ratelimiter.RateLimiter
+	// keyed by email as well
+	magicLinkLimiter ratelimiter.RateLimiter
+	lockout          lockout.Lockout
+	audit            *store.AuditStore
+	// failed logins are tracked per account and per ip
+	accountPolicy lockout.Policy
+	ipPolicy      lockout.Policy
+	// applied to every new password
+	passwordPolicy libs.PasswordPolicy
 }
 
-func NewAuthService(store *store.AuthStore) *AuthService {
-	return &AuthService{store: store}
+// NewAuthService takes the limiters of reset and magic link requests, they are keyed by email
+func NewAuthService(store *store.AuthStore, mailer mailer.Mailer, lock lockout.Lockout, resetLimiter ratelimiter.RateLimiter, magicLinkLimiter ratelimiter.RateLimiter, audit *store.AuditStore, config *configs.Config) *AuthService {
+	policy := lockout.Policy{
+		MaxFailures: config.LoginMaxFailures,
+		Window:      time.Duration(config.LoginFailureWindow) * time.Second,
+		BaseLockout: time.Duration(config.LoginLockoutBase) * time.Second,
+		MaxLockout:  time.Duration(config.LoginLockoutMax) * time.Second,
+	}
+
+	ipPolicy := policy
+	ipPolicy.MaxFailures = config.LoginIpMaxFailures
+
+	return &AuthService{
+		store:            store,
+		mailer:           mailer,
+		config:           config,
+		resetLimiter:     resetLimiter,
+		magicLinkLimiter: magicLinkLimiter,
+		lockout:          lock,
+		audit:            audit,
+		accountPolicy:    policy,
+		ipPolicy:         ipPolicy,
+		passwordPolicy: libs.PasswordPolicy{
+			MinLength:    config.PasswordMinLength,
+			MaxLength:    config.PasswordMaxLength,
+			MinClasses:   config.PasswordMinClasses,
+			RejectCommon: config.PasswordRejectCommon,
+		},
+	}
 }
 
 func (s *AuthService) Register(ctx context.Context, user types.UserRequestBody) (*types.User, error) {
-	return s.store.Register(ctx, user)
+	err := s.passwordPolicy.Validate(user.Password, user.Email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.store.Register(ctx, user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditRegistered, &res.Id, map[string]string{"method": "password"})
+
+	// the account exists at this point, a failed email can be resent later
+	err = s.sendVerification(ctx, res)
+
+	if err != nil {
+		zap.L().Error("Error sending verification email", zap.String("user", res.Id.String()), zap.Error(err))
+	}
+
+	return res, nil
 }
 
 func (s *AuthService) Login(ctx context.Context, user types.UserRequestBody) (*types.User, error) {
-	return s.store.Login(ctx, user)
+	account := lockout.AccountKey(user.Email)
+	ip, _ := ctx.Value(types.ClientIpKey("client-ip")).(string)
+
+	// unknown emails are tracked like any other, a lockout does not reveal the account exists
+	for _, key := range []string{account, lockout.IpKey(ip)} {
+		lockedFor, err := s.lockout.LockedFor(ctx, key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if lockedFor > 0 {
+			recordAudit(ctx, s.audit, types.AuditLoginFailed, nil, map[string]string{"email": user.Email, "reason": "locked"})
+			return nil, &types.LockedError{RetryAfter: lockedFor}
+		}
+	}
+
+	res, err := s.store.Login(ctx, user)
+
+	if errors.Is(err, types.ErrInvalidCredentials) {
+		recordAudit(ctx, s.audit, types.AuditLoginFailed, nil, map[string]string{"email": user.Email, "reason": "invalid_credentials"})
+		s.loginFailed(ctx, account, ip)
+		return nil, err
+	}
+
+	if errors.Is(err, types.ErrAccountDisabled) {
+		recordAudit(ctx, s.audit, types.AuditLoginFailed, nil, map[string]string{"email": user.Email, "reason": "account_disabled"})
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordLogin(ctx, s.audit, res, map[string]string{"method": "password"})
+
+	// the failures are kept until the second factor is given as well
+	if res.Token.MfaToken != "" {
+		return res, nil
+	}
+
+	// only the account is cleared, a valid login must not reset the ip of an attacker
+	err = s.lockout.Reset(ctx, account)
+
+	if err != nil {
+		zap.L().Error("Error resetting login failures", zap.String("user", res.Id.String()), zap.Error(err))
+	}
+
+	return res, nil
+}
+
+// loginFailed records a failed login for the account and the ip, logging any lockout it triggers
+func (s *AuthService) loginFailed(ctx context.Context, account string, ip string) {
+	keys := map[string]lockout.Policy{
+		account:           s.accountPolicy,
+		lockout.IpKey(ip): s.ipPolicy,
+	}
+
+	for key, policy := range keys {
+		failures, lockedFor, err := s.lockout.Fail(ctx, key, policy)
+
+		if err != nil {
+			zap.L().Error("Error recording failed login", zap.String("key", key), zap.Error(err))
+			continue
+		}
+
+		if lockedFor > 0 {
+			zap.L().Warn("Login locked out", zap.String("key", key), zap.Int("failures", failures), zap.Duration("duration", lockedFor))
+		}
+	}
+}
+
+func (s *AuthService) Refresh(ctx context.Context, req types.RefreshRequestBody) (*types.Token, error) {
+	res, err := s.store.Refresh(ctx, req)
+
+	var reused *types.ReusedTokenError
+
+	if errors.As(err, &reused) {
+		recordAudit(ctx, s.audit, types.AuditRefreshTokenReused, &reused.UserId, map[string]string{"session_id": reused.SessionId.String()})
+	}
+
+	return res, err
+}
+
+func (s *AuthService) Logout(ctx context.Context, req types.LogoutRequestBody) error {
+	err := s.store.Logout(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditLogout, currentUser(ctx), nil)
+
+	return nil
+}
+
+func (s *AuthService) LogoutAll(ctx context.Context, req types.LogoutAllRequestBody) error {
+	err := s.store.LogoutAll(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditLogoutAll, currentUser(ctx), nil)
+
+	return nil
+}
+
+func (s *AuthService) Verify(ctx context.Context, req types.VerifyRequestBody) error {
+	userId, err := s.store.Verify(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditEmailVerified, &userId, nil)
+
+	return nil
+}
+
+func (s *AuthService) ResendVerification(ctx context.Context, req types.ResendVerificationRequestBody) error {
+	user, err := s.store.GetUnverifiedUser(ctx, req.Email)
+
+	if err != nil {
+		return err
+	}
+
+	// unknown and already verified emails are silently ignored
+	if user == nil {
+		return nil
+	}
+
+	return s.sendVerification(ctx, user)
+}
+
+// ForgotPassword emails a reset link. It behaves the same whether or not the
+// email belongs to an account, so it cannot be used to enumerate users: the
+// account is looked up, the token stored and the email sent in the background.
+func (s *AuthService) ForgotPassword(ctx context.Context, req types.ForgotPasswordRequestBody) error {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	if allow, _ := s.resetLimiter.Allow(email); !allow {
+		zap.L().Warn("Password reset rate limited", zap.String("email", email))
+		return nil
+	}
+
+	go func(ctx context.Context) {
+		user, token, err := s.store.CreatePasswordReset(ctx, email, time.Duration(s.config.PasswordResetTTL)*time.Second)
+
+		if err != nil {
+			zap.L().Error("Error creating password reset", zap.Error(err))
+			return
+		}
+
+		if user == nil {
+			return
+		}
+
+		recordAudit(ctx, s.audit, types.AuditPasswordResetRequested, &user.Id, nil)
+
+		err = sendPasswordReset(ctx, s.mailer, s.config, user, token, "A password reset was requested for your TodoApp account.", " If you did not request it, ignore this email.")
+
+		if err != nil {
+			zap.L().Error("Error sending password reset email", zap.String("user", user.Id.String()), zap.Error(err))
+		}
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, req types.ResetPasswordRequestBody) error {
+	// the token is only resolved to an account inside the store
+	err := s.passwordPolicy.Validate(req.Password, "")
+
+	if err != nil {
+		return err
+	}
+
+	userId, err := s.store.ResetPassword(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditPasswordReset, &userId, nil)
+
+	return nil
+}
+
+// RequestMagicLink emails a sign-in link. Unknown emails get one as well and
+// the account is created once the link is used, so nothing is revealed.
+func (s *AuthService) RequestMagicLink(ctx context.Context, req types.MagicLinkRequestBody) error {
+	email := strings.TrimSpace(req.Email)
+
+	if allow, _ := s.magicLinkLimiter.Allow(strings.ToLower(email)); !allow {
+		zap.L().Warn("Magic link rate limited", zap.String("email", email))
+		return nil
+	}
+
+	token, err := s.store.CreateMagicLink(ctx, email, time.Duration(s.config.MagicLinkTTL)*time.Second)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditMagicLinkRequested, nil, map[string]string{"email": email})
+
+	return sendMagicLink(ctx, s.mailer, s.config, email, token)
+}
+
+func (s *AuthService) ConsumeMagicLink(ctx context.Context, req types.MagicLinkConsumeRequestBody) (*types.User, error) {
+	res, err := s.store.ConsumeMagicLink(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordLogin(ctx, s.audit, res, map[string]string{"method": "magic_link"})
+
+	return res, nil
+}
+
+// LoginMfa completes a login with the second factor. A wrong code counts as a
+// failed login of the account, once locked out the password step refuses to
+// hand out new challenges.
+func (s *AuthService) LoginMfa(ctx context.Context, req types.MfaLoginRequestBody) (*types.User, error) {
+	ip, _ := ctx.Value(types.ClientIpKey("client-ip")).(string)
+
+	res, err := s.store.LoginMfa(ctx, req)
+
+	var codeErr *types.InvalidCodeError
+
+	if errors.As(err, &codeErr) {
+		recordAudit(ctx, s.audit, types.AuditLoginFailed, nil, map[string]string{"email": codeErr.Email, "reason": "invalid_mfa_code"})
+		s.loginFailed(ctx, lockout.AccountKey(codeErr.Email), ip)
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordLogin(ctx, s.audit, res, map[string]string{"method": "mfa"})
+
+	// the login is complete, only now the failures of the account are cleared
+	err = s.lockout.Reset(ctx, lockout.AccountKey(res.Email))
+
+	if err != nil {
+		zap.L().Error("Error resetting login failures", zap.String("user", res.Id.String()), zap.Error(err))
+	}
+
+	return res, nil
+}
+
+func (s *AuthService) EnrollTotp(ctx context.Context) (*types.TotpEnrollment, error) {
+	return s.store.EnrollTotp(ctx)
+}
+
+func (s *AuthService) ConfirmTotp(ctx context.Context, req types.TotpCodeRequestBody) (*types.RecoveryCodes, error) {
+	res, err := s.store.ConfirmTotp(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditMfaEnabled, currentUser(ctx), nil)
+
+	return res, nil
+}
+
+func (s *AuthService) DisableTotp(ctx context.Context, req types.TotpCodeRequestBody) error {
+	err := s.store.DisableTotp(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditMfaDisabled, currentUser(ctx), nil)
+
+	return nil
+}
+
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, req types.TotpCodeRequestBody) (*types.RecoveryCodes, error) {
+	res, err := s.store.RegenerateRecoveryCodes(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditRecoveryCodesRegenerated, currentUser(ctx), nil)
+
+	return res, nil
+}
+
+func (s *AuthService) GetSessions(ctx context.Context) ([]types.Session, error) {
+	return s.store.GetSessions(ctx)
+}
+
+func (s *AuthService) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	err := s.store.DeleteSession(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditSessionRevoked, currentUser(ctx), map[string]string{"session_id": id.String()})
+
+	return nil
+}
+
+func (s *AuthService) GetMe(ctx context.Context) (*types.User, error) {
+	return s.store.Me(ctx)
+}
+
+func (s *AuthService) UpdateMe(ctx context.Context, req types.ProfilePatchRequestBody) (*types.User, error) {
+	err := req.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.UpdateMe(ctx, req)
+}
+
+func (s *AuthService) ChangePassword(ctx context.Context, req types.ChangePasswordRequestBody) error {
+	user, err := s.store.Me(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.passwordPolicy.Validate(req.NewPassword, user.Email)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.store.ChangePassword(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditPasswordChanged, &user.Id, nil)
+
+	return nil
+}
+
+// DeleteMe removes the account, the audit log keeps the events of the user
+func (s *AuthService) DeleteMe(ctx context.Context, req types.DeleteAccountRequestBody) error {
+	err := s.store.DeleteMe(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditAccountDeleted, currentUser(ctx), nil)
+
+	return nil
+}
+
+func (s *AuthService) sendVerification(ctx context.Context, user *types.User) error {
+	token, err := s.store.IssueVerificationToken(user)
+
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.config.AppUrl, url.QueryEscape(token))
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Welcome to TodoApp!\r\n\r\nConfirm your email address by opening the link below:\r\n\r\n%s\r\n\r\nThe link expires in 24 hours.", link),
+	})
+}
+
+// sendPasswordReset mails the reset link for token, intro explains why the user
+// receives it and note is appended to the expiry notice
+func sendPasswordReset(ctx context.Context, m mailer.Mailer, config *configs.Config, user *types.User, token string, intro string, note string) error {
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.AppUrl, url.QueryEscape(token))
+
+	return m.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("%s\r\n\r\nChoose a new password by opening the link below:\r\n\r\n%s\r\n\r\nThe link expires in %d minutes and works once.%s", intro, link, config.PasswordResetTTL/60, note),
+	})
+}
+
+// sendMagicLink emails a link signing in to the account of the email
+func sendMagicLink(ctx context.Context, m mailer.Mailer, config *configs.Config, email string, token string) error {
+	link := fmt.Sprintf("%s/magic-link?token=%s", config.AppUrl, url.QueryEscape(token))
+
+	return m.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Sign in to TodoApp",
+		Body:    fmt.Sprintf("Sign in to your TodoApp account by opening the link below:\r\n\r\n%s\r\n\r\nThe link expires in %d minutes and works once. If you did not request it, ignore this email.", link, config.MagicLinkTTL/60),
+	})
 }
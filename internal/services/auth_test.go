@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/configs"
+	"github.com/odev-swe/todoapp/internal/denylist"
+	"github.com/odev-swe/todoapp/internal/lockout"
+	"github.com/odev-swe/todoapp/internal/mailer"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendMagicLink(t *testing.T) {
+	outbox := mailer.NewOutboxMailer()
+	config := &configs.Config{AppUrl: "http://localhost:3000", MagicLinkTTL: 900}
+
+	err := sendMagicLink(context.Background(), outbox, config, "test@example.com", "a+b/c=")
+	require.NoError(t, err)
+
+	messages := outbox.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "test@example.com", messages[0].To)
+	assert.Contains(t, messages[0].Body, "expires in 15 minutes")
+
+	// the token survives the trip through the link
+	start := strings.Index(messages[0].Body, config.AppUrl)
+	require.NotEqual(t, -1, start)
+
+	link, err := url.Parse(strings.Fields(messages[0].Body[start:])[0])
+	require.NoError(t, err)
+	assert.Equal(t, "/magic-link", link.Path)
+	assert.Equal(t, "a+b/c=", link.Query().Get("token"))
+}
+
+// testAuthService runs against the migrated database in TEST_DATABASE_URL,
+// the test is skipped without one. Three failed logins lock an account.
+func testAuthService(t *testing.T) (*AuthService, *store.AuthStore) {
+	url := os.Getenv("TEST_DATABASE_URL")
+
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	hasher := libs.NewArgon2idHasher(libs.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	authStore := store.NewAuthStore(db, libs.NewHMACKeySet("secret"), denylist.NewMemoryDenylist(time.Hour), hasher)
+	config := &configs.Config{LoginMaxFailures: 3, LoginIpMaxFailures: 100, LoginFailureWindow: 600, LoginLockoutBase: 60, LoginLockoutMax: 600}
+
+	return NewAuthService(authStore, mailer.NewOutboxMailer(), lockout.NewMemoryLockout(), nil, nil, store.NewAuditStore(db), config), authStore
+}
+
+func TestLoginMfaLockout(t *testing.T) {
+	s, authStore := testAuthService(t)
+	ctx := context.WithValue(context.Background(), types.ClientIpKey("client-ip"), "203.0.113.7")
+	credentials := types.UserRequestBody{Email: uuid.NewString() + "@example.com", Password: "correct horse battery staple"}
+
+	user, err := authStore.Register(ctx, credentials)
+	require.NoError(t, err)
+
+	userCtx := context.WithValue(ctx, types.UserIdKey("user-id"), user.Id.String())
+
+	enrollment, err := authStore.EnrollTotp(userCtx)
+	require.NoError(t, err)
+
+	code, err := libs.TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+
+	_, err = authStore.ConfirmTotp(userCtx, types.TotpCodeRequestBody{Code: code})
+	require.NoError(t, err)
+
+	// the right password does not clear the failures of wrong codes
+	for i := 0; i < 3; i++ {
+		challenge, err := s.Login(ctx, credentials)
+		require.NoError(t, err)
+		require.NotEmpty(t, challenge.Token.MfaToken)
+
+		_, err = s.LoginMfa(ctx, types.MfaLoginRequestBody{MfaToken: challenge.Token.MfaToken, Code: "not-a-code"})
+		assert.ErrorIs(t, err, types.ErrInvalidCode)
+	}
+
+	_, err = s.Login(ctx, credentials)
+	assert.ErrorIs(t, err, types.ErrLoginLocked)
+}
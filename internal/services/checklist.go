@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type ChecklistService struct {
+	store *store.ChecklistStore
+}
+
+func NewChecklistService(store *store.ChecklistStore) *ChecklistService {
+	return &ChecklistService{store: store}
+}
+
+func (s *ChecklistService) Get(ctx context.Context, todoId uuid.UUID) (*types.Checklist, error) {
+	return s.store.Get(ctx, todoId)
+}
+
+func (s *ChecklistService) Add(ctx context.Context, todoId uuid.UUID, req types.ChecklistItemPostRequestBody) (*types.Checklist, error) {
+	title, err := types.NormalizeChecklistTitle(req.Title)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Title = title
+
+	return s.store.Add(ctx, todoId, req)
+}
+
+// Update renames an item or toggles it with done, missing fields are kept
+func (s *ChecklistService) Update(ctx context.Context, todoId uuid.UUID, id uuid.UUID, req types.ChecklistItemPatchRequestBody) (*types.Checklist, error) {
+	if req.Title != nil {
+		title, err := types.NormalizeChecklistTitle(*req.Title)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Title = &title
+	}
+
+	return s.store.Update(ctx, todoId, id, req)
+}
+
+func (s *ChecklistService) Reorder(ctx context.Context, todoId uuid.UUID, req types.ChecklistOrderRequestBody) (*types.Checklist, error) {
+	return s.store.Reorder(ctx, todoId, req.ItemIds)
+}
+
+func (s *ChecklistService) Delete(ctx context.Context, todoId uuid.UUID, id uuid.UUID) (*types.Checklist, error) {
+	return s.store.Delete(ctx, todoId, id)
+}
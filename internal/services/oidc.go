@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+
+	"github.com/odev-swe/todoapp/internal/oidc"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type OidcService struct {
+	authStore *store.AuthStore
+	store     *store.OidcStore
+	audit     *store.AuditStore
+	providers map[string]*oidc.Provider
+}
+
+func NewOidcService(authStore *store.AuthStore, store *store.OidcStore, audit *store.AuditStore, providers []*oidc.Provider) *OidcService {
+	byName := make(map[string]*oidc.Provider)
+
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &OidcService{authStore: authStore, store: store, audit: audit, providers: byName}
+}
+
+func (s *OidcService) AuthorizationUrl(ctx context.Context, provider string) (string, string, error) {
+	p, ok := s.providers[provider]
+
+	if !ok {
+		return "", "", types.ErrUnknownProvider
+	}
+
+	state, err := libs.RandomToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := libs.RandomToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	binding, err := libs.RandomToken(32)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	verifier, challenge, err := oidc.NewPKCE()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.store.SaveState(ctx, state, store.OidcState{Provider: provider, Nonce: nonce, Verifier: verifier, Binding: binding}, types.OidcStateTTL)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	url, err := p.AuthCodeURL(ctx, state, nonce, challenge)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return url, binding, nil
+}
+
+func (s *OidcService) Callback(ctx context.Context, provider string, code string, state string, binding string) (*types.User, error) {
+	p, ok := s.providers[provider]
+
+	if !ok {
+		return nil, types.ErrUnknownProvider
+	}
+
+	data, err := s.store.TakeState(ctx, state, binding)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the state was issued for another provider
+	if data.Provider != provider {
+		return nil, types.ErrInvalidState
+	}
+
+	token, err := p.Exchange(ctx, code, data.Verifier)
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.VerifyIDToken(ctx, token.IdToken, data.Nonce)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.authStore.LoginWithIdentity(ctx, types.ExternalIdentity{
+		Provider:      provider,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordLogin(ctx, s.audit, res, map[string]string{"method": "oidc", "provider": provider})
+
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type ProjectsService struct {
+	store *store.ProjectsStore
+	todos *store.TodosStore
+}
+
+func NewProjectsService(store *store.ProjectsStore, todos *store.TodosStore) *ProjectsService {
+	return &ProjectsService{store: store, todos: todos}
+}
+
+func (s *ProjectsService) Get(ctx context.Context, query types.ProjectsQuery) ([]types.Project, error) {
+	return s.store.Get(ctx, query)
+}
+
+func (s *ProjectsService) Create(ctx context.Context, req types.ProjectPostRequestBody) (*types.Project, error) {
+	name, err := types.NormalizeProjectName(req.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Name = name
+
+	if req.Color == "" {
+		req.Color = types.DefaultProjectColor
+	}
+
+	if err = types.ValidateProjectColor(req.Color); err != nil {
+		return nil, err
+	}
+
+	return s.store.Create(ctx, req)
+}
+
+func (s *ProjectsService) GetById(ctx context.Context, id uuid.UUID) (*types.Project, error) {
+	return s.store.GetById(ctx, id)
+}
+
+// Update renames, recolors, archives or moves a project, missing fields are kept
+func (s *ProjectsService) Update(ctx context.Context, id uuid.UUID, req types.ProjectPatchRequestBody) (*types.Project, error) {
+	if req.Name != nil {
+		name, err := types.NormalizeProjectName(*req.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Name = &name
+	}
+
+	if req.Color != nil {
+		if err := types.ValidateProjectColor(*req.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.store.Update(ctx, id, req)
+}
+
+// Delete removes a project, mode tells whether its todos go to the inbox, the
+// default, or are deleted with it
+func (s *ProjectsService) Delete(ctx context.Context, id uuid.UUID, mode string) error {
+	switch mode {
+	case "":
+		mode = types.ProjectDeleteInbox
+	case types.ProjectDeleteInbox, types.ProjectDeleteCascade:
+	default:
+		return types.ErrInvalidProjectDelete
+	}
+
+	return s.store.Delete(ctx, id, mode)
+}
+
+// GetTodos lists the todos of a project with the filters of the todo list
+func (s *ProjectsService) GetTodos(ctx context.Context, id uuid.UUID, query types.TodosQuery) (*types.TodosPage, error) {
+	// a missing project is reported rather than listed as empty
+	if _, err := s.store.GetById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	query.ProjectId = &id
+	query.Inbox = false
+
+	return s.todos.Get(ctx, query)
+}
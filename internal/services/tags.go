@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type TagsService struct {
+	store *store.TagsStore
+}
+
+func NewTagsService(store *store.TagsStore) *TagsService {
+	return &TagsService{store: store}
+}
+
+func (s *TagsService) Get(ctx context.Context) ([]types.Tag, error) {
+	return s.store.Get(ctx)
+}
+
+func (s *TagsService) Create(ctx context.Context, req types.TagPostRequestBody) (*types.Tag, error) {
+	name, err := types.NormalizeTagName(req.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Name = name
+
+	if req.Color == "" {
+		req.Color = types.DefaultTagColor
+	}
+
+	if err = types.ValidateTagColor(req.Color); err != nil {
+		return nil, err
+	}
+
+	return s.store.Create(ctx, req)
+}
+
+func (s *TagsService) GetById(ctx context.Context, id uuid.UUID) (*types.Tag, error) {
+	return s.store.GetById(ctx, id)
+}
+
+// Update renames or recolors a tag, missing fields are kept
+func (s *TagsService) Update(ctx context.Context, id uuid.UUID, req types.TagPatchRequestBody) (*types.Tag, error) {
+	if req.Name != nil {
+		name, err := types.NormalizeTagName(*req.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Name = &name
+	}
+
+	if req.Color != nil {
+		if err := types.ValidateTagColor(*req.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.store.Update(ctx, id, req)
+}
+
+func (s *TagsService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.store.Delete(ctx, id)
+}
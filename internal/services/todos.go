@@ -3,8 +3,10 @@ package services
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/odev-swe/todoapp/internal/store"
 	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
 )
 
 type TodosService struct {
@@ -15,18 +17,72 @@ func NewTodosService(store *store.TodosStore) *TodosService {
 	return &TodosService{store: store}
 }
 
-func (s *TodosService) Get(ctx context.Context) ([]types.Todos, error) {
-	return s.store.Get(ctx)
+func (s *TodosService) Get(ctx context.Context, query types.TodosQuery) (*types.TodosPage, error) {
+	return s.store.Get(ctx, query)
 }
 
 func (s *TodosService) Create(ctx context.Context, req types.TodosPostRequestBody) (*types.Todos, error) {
+	tags, err := types.NormalizeTagNames(req.Tags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Tags = tags
+
 	return s.store.Create(ctx, req)
 }
 
-func (s *TodosService) Update(ctx context.Context, req types.TodosPutRequestBody) (*types.Todos, error) {
-	return s.store.Update(ctx, req)
+// Search runs a full-text search of query.Q, every word matches as a prefix
+func (s *TodosService) Search(ctx context.Context, query types.TodosQuery) ([]types.TodoSearchResult, error) {
+	tsQuery, err := types.TodosTsQuery(query.Q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.Search(ctx, tsQuery, query)
+}
+
+func (s *TodosService) GetById(ctx context.Context, id uuid.UUID) (*types.Todos, error) {
+	return s.store.GetById(ctx, id)
+}
+
+// Update replaces a todo, nil tags are kept
+func (s *TodosService) Update(ctx context.Context, id uuid.UUID, req types.TodosPutRequestBody) (*types.Todos, error) {
+	if req.Tags != nil {
+		tags, err := types.NormalizeTagNames(req.Tags)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Tags = tags
+	}
+
+	return s.store.Update(ctx, id, req)
+}
+
+func (s *TodosService) Patch(ctx context.Context, id uuid.UUID, patch []libs.PatchField) (*types.Todos, error) {
+	for i, field := range patch {
+		tags, ok := field.Value.([]string)
+
+		if field.Column != "tags" || !ok {
+			continue
+		}
+
+		tags, err := types.NormalizeTagNames(tags)
+
+		if err != nil {
+			return nil, err
+		}
+
+		patch[i].Value = tags
+	}
+
+	return s.store.Patch(ctx, id, patch)
 }
 
-func (s *TodosService) Delete(ctx context.Context, req types.TodosDeleteRequestBody) error {
-	return s.store.Delete(ctx, req)
+func (s *TodosService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.store.Delete(ctx, id)
 }
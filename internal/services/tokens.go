@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type TokensService struct {
+	store *store.TokensStore
+	audit *store.AuditStore
+}
+
+func NewTokensService(store *store.TokensStore, audit *store.AuditStore) *TokensService {
+	return &TokensService{store: store, audit: audit}
+}
+
+func (s *TokensService) Create(ctx context.Context, req types.PersonalAccessTokenPostRequestBody) (*types.PersonalAccessToken, error) {
+	if err := types.ValidateScopes(req.Scopes); err != nil {
+		return nil, err
+	}
+
+	res, err := s.store.Create(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditTokenCreated, currentUser(ctx), map[string]string{"token_id": res.Id.String(), "name": res.Name})
+
+	return res, nil
+}
+
+func (s *TokensService) Get(ctx context.Context) ([]types.PersonalAccessToken, error) {
+	return s.store.Get(ctx)
+}
+
+func (s *TokensService) GetById(ctx context.Context, id uuid.UUID) (*types.PersonalAccessToken, error) {
+	return s.store.GetById(ctx, id)
+}
+
+func (s *TokensService) Update(ctx context.Context, id uuid.UUID, req types.PersonalAccessTokenPatchRequestBody) (*types.PersonalAccessToken, error) {
+	if req.Scopes != nil {
+		if err := types.ValidateScopes(*req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.store.Update(ctx, id, req)
+}
+
+func (s *TokensService) Delete(ctx context.Context, id uuid.UUID) error {
+	err := s.store.Delete(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, types.AuditTokenRevoked, currentUser(ctx), map[string]string{"token_id": id.String()})
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/store"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type WorkspacesService struct {
+	store *store.WorkspacesStore
+}
+
+func NewWorkspacesService(store *store.WorkspacesStore) *WorkspacesService {
+	return &WorkspacesService{store: store}
+}
+
+func (s *WorkspacesService) Get(ctx context.Context) ([]types.Workspace, error) {
+	return s.store.Get(ctx)
+}
+
+func (s *WorkspacesService) Create(ctx context.Context, req types.WorkspacePostRequestBody) (*types.Workspace, error) {
+	req.Name = strings.TrimSpace(req.Name)
+
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > 255 {
+		return nil, types.ErrInvalidWorkspaceName
+	}
+
+	return s.store.Create(ctx, req)
+}
+
+func (s *WorkspacesService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.store.Delete(ctx, id)
+}
+
+func (s *WorkspacesService) GetMembers(ctx context.Context, id uuid.UUID) ([]types.WorkspaceMember, error) {
+	return s.store.GetMembers(ctx, id)
+}
+
+func (s *WorkspacesService) AddMember(ctx context.Context, id uuid.UUID, req types.WorkspaceMemberPostRequestBody) (*types.WorkspaceMember, error) {
+	// new members default to the member role
+	if req.Role == "" {
+		req.Role = types.WorkspaceRoleMember
+	}
+
+	if !slices.Contains(types.WorkspaceRoles, req.Role) {
+		return nil, types.ErrInvalidWorkspaceRole
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+
+	return s.store.AddMember(ctx, id, req)
+}
+
+func (s *WorkspacesService) UpdateMember(ctx context.Context, id uuid.UUID, userId uuid.UUID, req types.WorkspaceMemberPatchRequestBody) (*types.WorkspaceMember, error) {
+	if !slices.Contains(types.WorkspaceRoles, req.Role) {
+		return nil, types.ErrInvalidWorkspaceRole
+	}
+
+	return s.store.UpdateMember(ctx, id, userId, req)
+}
+
+func (s *WorkspacesService) RemoveMember(ctx context.Context, id uuid.UUID, userId uuid.UUID) error {
+	return s.store.RemoveMember(ctx, id, userId)
+}
@@ -0,0 +1,338 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+// Me returns the profile of the current user
+func (s *AuthStore) Me(ctx context.Context) (*types.User, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var user types.User
+
+	prepareQuery := "SELECT " + userColumns + " FROM users WHERE id = $1"
+
+	err = scanUser(conn.QueryRow(ctx, prepareQuery, uuidUserId), &user)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UpdateMe changes the profile fields set in the request
+func (s *AuthStore) UpdateMe(ctx context.Context, req types.ProfilePatchRequestBody) (*types.User, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var user types.User
+
+	// unset fields keep their current value
+	prepareQuery := `UPDATE users SET display_name = COALESCE($2, display_name), timezone = COALESCE($3, timezone), locale = COALESCE($4, locale), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 RETURNING ` + userColumns
+
+	err = scanUser(conn.QueryRow(ctx, prepareQuery, uuidUserId, req.DisplayName, req.Timezone, req.Locale), &user)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ChangePassword replaces the password of the current user after
+// reauthenticating them, every other session and every personal access token
+// of the user is ended. Accounts without a password set their first one.
+func (s *AuthStore) ChangePassword(ctx context.Context, req types.ChangePasswordRequestBody) error {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	// the session making the request stays logged in
+	currentId, _ := ctx.Value(types.SessionIdKey("session-id")).(string)
+	uuidCurrentId, _ := uuid.Parse(currentId)
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	err = s.reauthenticate(ctx, tx, uuidUserId, req.CurrentPassword)
+
+	if err != nil {
+		return err
+	}
+
+	// hash the password
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", hashedPassword, uuidUserId)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL", uuidUserId, uuidCurrentId)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL RETURNING id", uuidUserId, uuidCurrentId)
+
+	if err != nil {
+		return err
+	}
+
+	ended, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+
+	if err != nil {
+		return err
+	}
+
+	// password reset links requested with the old password stop working
+	_, err = tx.Exec(ctx, "UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL", uuidUserId)
+
+	if err != nil {
+		return err
+	}
+
+	err = deleteUserTokens(ctx, tx, uuidUserId, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, sessionId := range ended {
+		if err = s.revokeSessionTokens(ctx, sessionId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMe deletes the current user after reauthenticating them, everything
+// the user owns is removed along with it. Shared workspaces the user is the
+// only member of go too, those with other members need another owner first.
+func (s *AuthStore) DeleteMe(ctx context.Context, req types.DeleteAccountRequestBody) error {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	err = s.reauthenticate(ctx, tx, uuidUserId, req.Password)
+
+	if err != nil {
+		return err
+	}
+
+	var lastOwner bool
+
+	prepareQuery := `SELECT EXISTS (
+		SELECT 1 FROM workspace_members m JOIN workspaces w ON w.id = m.workspace_id
+		WHERE m.user_id = $1 AND m.role = $2 AND w.personal_user_id IS NULL
+			AND NOT EXISTS (SELECT 1 FROM workspace_members o WHERE o.workspace_id = w.id AND o.user_id <> $1 AND o.role = $2)
+			AND EXISTS (SELECT 1 FROM workspace_members o WHERE o.workspace_id = w.id AND o.user_id <> $1)
+	)`
+
+	err = tx.QueryRow(ctx, prepareQuery, uuidUserId, types.WorkspaceRoleOwner).Scan(&lastOwner)
+
+	if err != nil {
+		return err
+	}
+
+	if lastOwner {
+		return types.ErrLastOwner
+	}
+
+	prepareQuery = `DELETE FROM workspaces w WHERE w.personal_user_id IS NULL
+		AND EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id = $1)
+		AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id <> $1)`
+
+	_, err = tx.Exec(ctx, prepareQuery, uuidUserId)
+
+	if err != nil {
+		return err
+	}
+
+	// the personal workspace with its todos, sessions and tokens cascade
+	_, err = tx.Exec(ctx, "DELETE FROM users WHERE id = $1", uuidUserId)
+
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// access tokens outlive the rows they were issued for
+	return s.denylist.RevokeUser(ctx, uuidUserId.String(), time.Now())
+}
+
+// errNoPassword is returned by checkPassword for accounts that log in through a
+// magic link or an identity provider
+var errNoPassword = fmt.Errorf("%w: the account has no password", types.ErrInvalidPassword)
+
+// checkPassword locks the user row and compares the password, accounts without
+// a password never match
+func (s *AuthStore) checkPassword(ctx context.Context, db executor, userId uuid.UUID, password string) error {
+	var hash *string
+
+	err := db.QueryRow(ctx, "SELECT password FROM users WHERE id = $1 FOR UPDATE", userId).Scan(&hash)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return types.ErrUserNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if hash == nil {
+		return errNoPassword
+	}
+
+	ok, _, err := s.hasher.Verify(*hash, password)
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return types.ErrInvalidPassword
+	}
+
+	return nil
+}
+
+// reauthenticate checks the password of the user, accounts without one prove
+// themselves with a session started within the reauthentication window instead
+func (s *AuthStore) reauthenticate(ctx context.Context, db executor, userId uuid.UUID, password string) error {
+	err := s.checkPassword(ctx, db, userId, password)
+
+	if !errors.Is(err, errNoPassword) {
+		return err
+	}
+
+	// personal access tokens carry no session and cannot reauthenticate
+	sessionId, _ := ctx.Value(types.SessionIdKey("session-id")).(string)
+	uuidSessionId, err := uuid.Parse(sessionId)
+
+	if err != nil {
+		return types.ErrReauthRequired
+	}
+
+	var recent bool
+
+	prepareQuery := `SELECT EXISTS (
+		SELECT 1 FROM sessions WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+			AND created_at > CURRENT_TIMESTAMP - $3 * INTERVAL '1 second'
+	)`
+
+	err = db.QueryRow(ctx, prepareQuery, uuidSessionId, userId, types.ReauthWindow.Seconds()).Scan(&recent)
+
+	if err != nil {
+		return err
+	}
+
+	if !recent {
+		return types.ErrReauthRequired
+	}
+
+	return nil
+}
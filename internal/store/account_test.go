@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteMe(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	user, ctx := testUser(t, s)
+
+	assert.ErrorIs(t, s.DeleteMe(ctx, types.DeleteAccountRequestBody{Password: "wrong"}), types.ErrInvalidPassword)
+	require.NoError(t, s.DeleteMe(ctx, types.DeleteAccountRequestBody{Password: "correct horse battery staple"}))
+
+	_, err := s.Me(ctx)
+	assert.ErrorIs(t, err, types.ErrUserNotFound)
+
+	// the access tokens of the deleted user are denied
+	cutoff, err := s.denylist.RevokedBefore(ctx, user.Id.String())
+	require.NoError(t, err)
+	assert.False(t, cutoff.IsZero())
+}
+
+func TestDeleteMeWithoutPassword(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	user, ctx := testPasswordlessUser(t, s)
+	sessionId := ctx.Value(types.SessionIdKey("session-id"))
+
+	// a personal access token carries no session
+	patCtx := context.WithValue(context.Background(), types.UserIdKey("user-id"), user.Id.String())
+	assert.ErrorIs(t, s.DeleteMe(patCtx, types.DeleteAccountRequestBody{}), types.ErrReauthRequired)
+
+	// a session started before the window no longer counts as a fresh login
+	_, err := db.Exec(ctx, "UPDATE sessions SET created_at = CURRENT_TIMESTAMP - INTERVAL '1 hour' WHERE id = $1", sessionId)
+	require.NoError(t, err)
+	assert.ErrorIs(t, s.DeleteMe(ctx, types.DeleteAccountRequestBody{}), types.ErrReauthRequired)
+
+	_, err = db.Exec(ctx, "UPDATE sessions SET created_at = CURRENT_TIMESTAMP WHERE id = $1", sessionId)
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteMe(ctx, types.DeleteAccountRequestBody{}))
+
+	_, err = s.Me(ctx)
+	assert.ErrorIs(t, err, types.ErrUserNotFound)
+}
+
+func TestChangePasswordWithoutPassword(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	user, ctx := testPasswordlessUser(t, s)
+	sessionId := ctx.Value(types.SessionIdKey("session-id"))
+
+	_, err := db.Exec(ctx, "UPDATE sessions SET created_at = CURRENT_TIMESTAMP - INTERVAL '1 hour' WHERE id = $1", sessionId)
+	require.NoError(t, err)
+	assert.ErrorIs(t, s.ChangePassword(ctx, types.ChangePasswordRequestBody{NewPassword: "correct horse battery staple"}), types.ErrReauthRequired)
+
+	_, err = db.Exec(ctx, "UPDATE sessions SET created_at = CURRENT_TIMESTAMP WHERE id = $1", sessionId)
+	require.NoError(t, err)
+	require.NoError(t, s.ChangePassword(ctx, types.ChangePasswordRequestBody{NewPassword: "correct horse battery staple"}))
+
+	_, err = s.Login(context.Background(), types.UserRequestBody{Email: user.Email, Password: "correct horse battery staple"})
+	require.NoError(t, err)
+
+	// from now on the password is what proves the user
+	err = s.ChangePassword(ctx, types.ChangePasswordRequestBody{NewPassword: "another horse battery staple"})
+	assert.ErrorIs(t, err, types.ErrInvalidPassword)
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+// DisableUser blocks the user from logging in and revokes every token and session they hold,
+// personal access tokens included
+func (s *AuthStore) DisableUser(ctx context.Context, id uuid.UUID) error {
+	return s.lockOut(ctx, id, "UPDATE users SET disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP WHERE id = $1")
+}
+
+func (s *AuthStore) EnableUser(ctx context.Context, id uuid.UUID) error {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, "UPDATE users SET disabled_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return types.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ForcePasswordReset clears the password of the user and revokes every token
+// and session they hold, personal access tokens included. It returns a reset
+// token so the user can choose a new one
+func (s *AuthStore) ForcePasswordReset(ctx context.Context, id uuid.UUID, ttl time.Duration) (*types.User, string, error) {
+	err := s.lockOut(ctx, id, "UPDATE users SET password = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1")
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	var email string
+
+	err = conn.QueryRow(ctx, "SELECT email FROM users WHERE id = $1", id).Scan(&email)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.CreatePasswordReset(ctx, email, ttl)
+}
+
+// SetRole changes the role of the user. Access tokens carry the role, the ones
+// already issued are revoked and the next refresh picks the new role up.
+func (s *AuthStore) SetRole(ctx context.Context, id uuid.UUID, role string) error {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx, "UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", role, id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return types.ErrUserNotFound
+	}
+
+	return s.denylist.RevokeUser(ctx, id.String(), time.Now())
+}
+
+// lockOut applies the given update to the user and revokes their refresh tokens,
+// sessions, outstanding access tokens and personal access tokens
+func (s *AuthStore) lockOut(ctx context.Context, id uuid.UUID, prepareQuery string) error {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, prepareQuery, id)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return types.ErrUserNotFound
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", id)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.endOrphanedSessions(ctx, tx, id)
+
+	if err != nil {
+		return err
+	}
+
+	// personal access tokens are not denylisted, they have to go
+	err = deleteUserTokens(ctx, tx, id, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return s.denylist.RevokeUser(ctx, id.String(), time.Now())
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisableUser(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	tokens := NewTokensStore(db)
+	user, ctx := testUser(t, s)
+
+	pat, err := tokens.Create(ctx, types.PersonalAccessTokenPostRequestBody{Name: "ci"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DisableUser(context.Background(), user.Id))
+
+	// the access tokens issued so far are denied
+	cutoff, err := s.denylist.RevokedBefore(ctx, user.Id.String())
+	require.NoError(t, err)
+	assert.False(t, cutoff.IsZero())
+
+	_, _, err = tokens.Authenticate(ctx, pat.Token)
+	assert.ErrorIs(t, err, types.ErrInvalidToken)
+
+	// enabling the user does not bring the personal access tokens back
+	require.NoError(t, s.EnableUser(context.Background(), user.Id))
+
+	_, _, err = tokens.Authenticate(ctx, pat.Token)
+	assert.ErrorIs(t, err, types.ErrInvalidToken)
+
+	assert.ErrorIs(t, s.DisableUser(context.Background(), uuid.New()), types.ErrUserNotFound)
+	assert.ErrorIs(t, s.EnableUser(context.Background(), uuid.New()), types.ErrUserNotFound)
+}
+
+func TestSetRole(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	user, ctx := testUser(t, s)
+
+	require.NoError(t, s.SetRole(context.Background(), user.Id, types.RoleAdmin))
+
+	me, err := s.Me(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.RoleAdmin, me.Role)
+
+	// tokens carrying the old role are denied
+	cutoff, err := s.denylist.RevokedBefore(ctx, user.Id.String())
+	require.NoError(t, err)
+	assert.False(t, cutoff.IsZero())
+
+	assert.ErrorIs(t, s.SetRole(context.Background(), uuid.New(), types.RoleUser), types.ErrUserNotFound)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	tokens := NewTokensStore(db)
+	user, ctx := testUser(t, s)
+
+	pat, err := tokens.Create(ctx, types.PersonalAccessTokenPostRequestBody{Name: "ci"})
+	require.NoError(t, err)
+
+	resetUser, resetToken, err := s.ForcePasswordReset(context.Background(), user.Id, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, resetUser.Email)
+	assert.NotEmpty(t, resetToken)
+
+	// the personal access tokens are gone, not only denied
+	_, _, err = tokens.Authenticate(ctx, pat.Token)
+	assert.ErrorIs(t, err, types.ErrInvalidToken)
+
+	remaining, err := tokens.Get(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	// the password no longer works
+	_, err = s.Login(context.Background(), types.UserRequestBody{Email: user.Email, Password: "correct horse battery staple"})
+	assert.ErrorIs(t, err, types.ErrInvalidCredentials)
+
+	_, _, err = s.ForcePasswordReset(context.Background(), uuid.New(), time.Hour)
+	assert.ErrorIs(t, err, types.ErrUserNotFound)
+}
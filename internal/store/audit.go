@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+// auditChainLock is the advisory lock serializing appends, so every event
+// links to the one committed before it
+const auditChainLock = 0x617564697400
+
+type AuditStore struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditStore(db *pgxpool.Pool) *AuditStore {
+	return &AuditStore{
+		db: db,
+	}
+}
+
+const auditColumns = "id, event_type, actor_id, ip, user_agent, metadata, created_at, prev_hash, hash"
+
+func scanAuditEvent(row pgx.Row, event *types.AuditEvent) error {
+	return row.Scan(&event.Id, &event.Type, &event.ActorId, &event.Ip, &event.UserAgent, &event.Metadata, &event.CreatedAt, &event.PrevHash, &event.Hash)
+}
+
+// Record appends an event to the log, the client ip and user agent are taken
+// from the context
+func (s *AuditStore) Record(ctx context.Context, eventType string, actorId *uuid.UUID, metadata map[string]string) error {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	// the column keeps microseconds, the hash must cover what is stored
+	event := types.AuditEvent{
+		Type:      eventType,
+		ActorId:   actorId,
+		Metadata:  metadata,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	event.Ip, _ = ctx.Value(types.ClientIpKey("client-ip")).(string)
+	event.UserAgent, _ = ctx.Value(types.UserAgentKey("user-agent")).(string)
+
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", auditChainLock)
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, "SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1").Scan(&event.PrevHash)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		event.PrevHash = types.AuditGenesisHash
+	} else if err != nil {
+		return err
+	}
+
+	event.Hash = event.ComputeHash()
+
+	prepareQuery := "INSERT INTO audit_events (event_type, actor_id, ip, user_agent, metadata, created_at, prev_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+
+	_, err = tx.Exec(ctx, prepareQuery, event.Type, event.ActorId, event.Ip, event.UserAgent, event.Metadata, event.CreatedAt, event.PrevHash, event.Hash)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Get returns the events matching the query, newest first
+func (s *AuditStore) Get(ctx context.Context, query types.AuditQuery) ([]types.AuditEvent, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// the column has no time zone, bounds are compared in utc
+	var from, to *time.Time
+
+	if query.From != nil {
+		t := query.From.UTC()
+		from = &t
+	}
+
+	if query.To != nil {
+		t := query.To.UTC()
+		to = &t
+	}
+
+	// missing filters match everything
+	prepareQuery := `SELECT ` + auditColumns + ` FROM audit_events
+		WHERE ($1 = '' OR event_type = $1) AND ($2::uuid IS NULL OR actor_id = $2)
+			AND ($3::timestamp IS NULL OR created_at >= $3) AND ($4::timestamp IS NULL OR created_at < $4)
+		ORDER BY id DESC LIMIT $5 OFFSET $6`
+
+	rows, err := conn.Query(ctx, prepareQuery, query.Type, query.ActorId, from, to, query.Limit, query.Offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := []types.AuditEvent{}
+
+	for rows.Next() {
+		var event types.AuditEvent
+
+		err = scanAuditEvent(rows, &event)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// Verify walks the whole log recomputing every hash, it stops at the first
+// event that was changed or does not follow the one before it
+func (s *AuditStore) Verify(ctx context.Context) (*types.AuditVerification, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, "SELECT "+auditColumns+" FROM audit_events ORDER BY id")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	result := &types.AuditVerification{Valid: true}
+	prevHash := types.AuditGenesisHash
+
+	for rows.Next() {
+		var event types.AuditEvent
+
+		err = scanAuditEvent(rows, &event)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.Checked++
+
+		if event.PrevHash != prevHash || event.ComputeHash() != event.Hash {
+			result.Valid = false
+			result.BrokenAt = &event.Id
+
+			return result, nil
+		}
+
+		prevHash = event.Hash
+	}
+
+	return result, rows.Err()
+}
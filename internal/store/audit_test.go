@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tamperAuditEvent rewrites the metadata of an event behind the append only
+// trigger, the way someone with direct access to the database could
+func tamperAuditEvent(t *testing.T, s *AuditStore, id int64, metadata map[string]string) {
+	ctx := context.Background()
+
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "SET LOCAL session_replication_role = replica")
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE audit_events SET metadata = $1 WHERE id = $2", metadata, id)
+
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestAuditChain(t *testing.T) {
+	db := testDB(t)
+	s := NewAuditStore(db)
+	ctx := context.WithValue(context.Background(), types.ClientIpKey("client-ip"), "203.0.113.7")
+
+	actor := uuid.New()
+
+	for _, eventType := range []string{types.AuditLoginFailed, types.AuditLoginSucceeded, types.AuditLogout} {
+		require.NoError(t, s.Record(ctx, eventType, &actor, map[string]string{"method": "password"}))
+	}
+
+	events, err := s.Get(ctx, types.AuditQuery{ActorId: &actor, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	// newest first, each event links to the one recorded before it
+	assert.Equal(t, types.AuditLogout, events[0].Type)
+	assert.Equal(t, events[1].Hash, events[0].PrevHash)
+	assert.Equal(t, events[2].Hash, events[1].PrevHash)
+	assert.Equal(t, "203.0.113.7", events[0].Ip)
+
+	result, err := s.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Nil(t, result.BrokenAt)
+	assert.GreaterOrEqual(t, result.Checked, 3)
+
+	// rows can not be changed through normal statements
+	_, err = db.Exec(ctx, "UPDATE audit_events SET metadata = '{}' WHERE id = $1", events[1].Id)
+	assert.Error(t, err)
+
+	tampered := events[1]
+	tamperAuditEvent(t, s, tampered.Id, map[string]string{"method": "magic_link"})
+	t.Cleanup(func() { tamperAuditEvent(t, s, tampered.Id, tampered.Metadata) })
+
+	result, err = s.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenAt)
+	assert.Equal(t, tampered.Id, *result.BrokenAt)
+}
@@ -2,22 +2,48 @@ package store
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/denylist"
 	"github.com/odev-swe/todoapp/internal/types"
 	"github.com/odev-swe/todoapp/libs"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 )
 
+// executor is implemented by both pooled connections and transactions
+type executor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type AuthStore struct {
-	db     *pgxpool.Pool
-	secret string
+	db       *pgxpool.Pool
+	keys     *libs.KeySet
+	denylist denylist.Denylist
+	hasher   libs.PasswordHasher
+	// compared against when a login has no password, so it takes as long as a wrong password
+	dummyHash string
 }
 
-func NewAuthStore(db *pgxpool.Pool, secret string) *AuthStore {
+func NewAuthStore(db *pgxpool.Pool, keys *libs.KeySet, denylist denylist.Denylist, hasher libs.PasswordHasher) *AuthStore {
+	dummyHash, err := hasher.Hash(uuid.NewString())
+
+	if err != nil {
+		panic(err)
+	}
+
 	return &AuthStore{
-		db:     db,
-		secret: secret,
+		db:        db,
+		keys:      keys,
+		denylist:  denylist,
+		hasher:    hasher,
+		dummyHash: dummyHash,
 	}
 }
 
@@ -34,24 +60,42 @@ func (s *AuthStore) Register(ctx context.Context, req types.UserRequestBody) (*t
 	defer conn.Release()
 
 	// hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(req.Password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.Begin(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
+	defer tx.Rollback(ctx)
+
 	var user types.User
 
 	// perform the query
 	// register query statement
 	prepareQuery := "INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id, email, created_at, updated_at"
 
-	err = conn.QueryRow(ctx, prepareQuery, req.Email, hashedPassword).Scan(&user.Id, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	err = tx.QueryRow(ctx, prepareQuery, req.Email, hashedPassword).Scan(&user.Id, &user.Email, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return nil, err
 	}
 
+	err = createPersonalWorkspace(ctx, tx, user.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -68,43 +112,734 @@ func (s *AuthStore) Login(ctx context.Context, req types.UserRequestBody) (*type
 	defer conn.Release()
 
 	var user types.User
+	var password *string
+	var totpEnabledAt *time.Time
 
 	// perform the query
 	// login query statement
-	prepareQuery := "SELECT id, email,password, created_at, updated_at FROM users WHERE email = $1"
+	prepareQuery := "SELECT id, email,password, verified_at, role, disabled_at, created_at, updated_at, totp_enabled_at FROM users WHERE email = $1"
 
-	err = conn.QueryRow(ctx, prepareQuery, req.Email).Scan(&user.Id, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err = conn.QueryRow(ctx, prepareQuery, req.Email).Scan(&user.Id, &user.Email, &password, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt, &totpEnabledAt)
 
-	if err != nil {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, err
 	}
 
+	// unknown emails and accounts created through an identity provider have no
+	// password, compare against a dummy hash so they take as long as a wrong password
+	hash := s.dummyHash
+
+	if password != nil {
+		hash = *password
+	}
+
 	// compare the password
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	ok, needsRehash, err := s.hasher.Verify(hash, req.Password)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if !ok || password == nil {
+		return nil, types.ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		s.rehash(ctx, conn, user.Id, hash, req.Password)
+	}
+
+	return s.completeLogin(ctx, conn, user, totpEnabledAt)
+}
+
+// LoginWithIdentity signs in the user linked to an external identity. A new
+// identity is linked to the account owning the same email, or to a new
+// passwordless account, as long as the provider verified the email.
+// Linking an unverified account claims it, see claimAccount.
+func (s *AuthStore) LoginWithIdentity(ctx context.Context, identity types.ExternalIdentity) (*types.User, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var user types.User
+	var totpEnabledAt *time.Time
+	// sessions of an unverified registrant, ended when the account is claimed
+	var ended []uuid.UUID
+
+	prepareQuery := "SELECT u.id, u.email, u.verified_at, u.role, u.disabled_at, u.created_at, u.updated_at, u.totp_enabled_at FROM user_identities i JOIN users u ON u.id = i.user_id WHERE i.provider = $1 AND i.subject = $2"
+
+	err = tx.QueryRow(ctx, prepareQuery, identity.Provider, identity.Subject).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt, &totpEnabledAt)
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// first login with this identity
+	if errors.Is(err, pgx.ErrNoRows) {
+		if !identity.EmailVerified || identity.Email == "" {
+			return nil, types.ErrEmailNotVerified
+		}
+
+		prepareQuery = "SELECT id, email, verified_at, role, disabled_at, created_at, updated_at, totp_enabled_at FROM users WHERE email = $1 FOR UPDATE"
+
+		err = tx.QueryRow(ctx, prepareQuery, identity.Email).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt, &totpEnabledAt)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			prepareQuery = "INSERT INTO users (email, verified_at) VALUES ($1, CURRENT_TIMESTAMP) RETURNING id, email, verified_at, role, created_at, updated_at"
+
+			err = tx.QueryRow(ctx, prepareQuery, identity.Email).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+
+			if err == nil {
+				err = createPersonalWorkspace(ctx, tx, user.Id)
+			}
+		} else if err == nil && user.VerifiedAt == nil {
+			// the provider proved ownership of the email
+			ended, err = s.claimAccount(ctx, tx, &user)
+			totpEnabledAt = nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		prepareQuery = "INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)"
+
+		_, err = tx.Exec(ctx, prepareQuery, user.Id, identity.Provider, identity.Subject, identity.Email)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := s.completeLogin(ctx, tx, user, totpEnabledAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, sessionId := range ended {
+		if err = s.revokeSessionTokens(ctx, sessionId); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// claimAccount verifies the email of an unverified account for whoever just
+// proved owning it. Anything set up without that proof is dropped, so whoever
+// registered the email first can not keep a way in: the password, 2fa,
+// personal access tokens and sessions. The ended sessions are returned for
+// their access tokens to be revoked once the transaction commits.
+func (s *AuthStore) claimAccount(ctx context.Context, tx pgx.Tx, user *types.User) ([]uuid.UUID, error) {
+	prepareQuery := `UPDATE users SET verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, password = NULL,
+		totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = $1 RETURNING verified_at`
+
+	err := tx.QueryRow(ctx, prepareQuery, user.Id).Scan(&user.VerifiedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", user.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = deleteUserTokens(ctx, tx, user.Id, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", user.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL RETURNING id", user.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// rehash upgrades a verified password to the current hasher, a failure only
+// postpones the upgrade to the next login
+func (s *AuthStore) rehash(ctx context.Context, db executor, userId uuid.UUID, oldHash string, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+
+	if err == nil {
+		// skip the update when the password changed in the meantime
+		_, err = db.Exec(ctx, "UPDATE users SET password = $1 WHERE id = $2 AND password = $3", hashedPassword, userId, oldHash)
+	}
+
+	if err != nil {
+		zap.L().Warn("Error rehashing password", zap.String("user", userId.String()), zap.Error(err))
+	}
+}
+
+// completeLogin hands out the token pair for an authenticated user, or the
+// challenge for LoginMfa when the user has 2fa enabled
+func (s *AuthStore) completeLogin(ctx context.Context, db executor, user types.User, totpEnabledAt *time.Time) (*types.User, error) {
 	// clear the password
 	user.Password = ""
 
+	if user.DisabledAt != nil {
+		return nil, types.ErrAccountDisabled
+	}
+
+	// with 2fa enabled the first factor only buys a challenge for LoginMfa
+	if totpEnabledAt != nil {
+		mfaToken, err := libs.GenerateToken(libs.CustomClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: user.Id.String()},
+			Type:             libs.MfaPendingToken,
+		}, s.keys)
+
+		if err != nil {
+			return nil, err
+		}
+
+		user.Token.MfaToken = mfaToken
+
+		return &user, nil
+	}
+
+	// every login starts a new refresh token family
+	token, err := s.issueTokens(ctx, db, user, uuid.New())
+
+	if err != nil {
+		return nil, err
+	}
+
+	user.Token = *token
+
+	return &user, nil
+}
+
+// Refresh exchanges a refresh token for a new access/refresh pair.
+// The presented token is revoked; presenting an already revoked token
+// revokes every token of its family.
+func (s *AuthStore) Refresh(ctx context.Context, req types.RefreshRequestBody) (*types.Token, error) {
+	claims, err := libs.ParseToken(req.RefreshToken, s.keys, libs.RefreshToken)
+
+	if err != nil {
+		return nil, types.ErrInvalidToken
+	}
+
+	tokenId, err := uuid.Parse(claims.ID)
+
+	if err != nil {
+		return nil, types.ErrInvalidToken
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var user types.User
+	var familyId uuid.UUID
+	var revokedAt *time.Time
+
+	// lock the row so concurrent refreshes of the same token are serialized
+	prepareQuery := "SELECT user_id, family_id, revoked_at FROM refresh_tokens WHERE id = $1 FOR UPDATE"
+
+	err = tx.QueryRow(ctx, prepareQuery, tokenId).Scan(&user.Id, &familyId, &revokedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt != nil {
+		// the token was already rotated, assume it leaked and kill the whole session
+		_, err = s.endSession(ctx, tx, user.Id, familyId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+
+		if err = s.revokeSessionTokens(ctx, familyId); err != nil {
+			return nil, err
+		}
+
+		return nil, &types.ReusedTokenError{UserId: user.Id, SessionId: familyId}
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1", tokenId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prepareQuery = "SELECT id, email, verified_at, role, disabled_at, created_at, updated_at FROM users WHERE id = $1"
+
+	err = tx.QueryRow(ctx, prepareQuery, user.Id).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.issueTokens(ctx, tx, user, familyId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Logout revokes the access token of the current request and, when given,
+// the refresh token family it was issued with
+func (s *AuthStore) Logout(ctx context.Context, req types.LogoutRequestBody) error {
+	// get token details from context
+	tokenId := ctx.Value(types.TokenIdKey("token-id")).(string)
+	expiresAt := ctx.Value(types.TokenExpiresAtKey("token-expires-at")).(time.Time)
+
+	err := s.denylist.Revoke(ctx, tokenId, expiresAt)
+
+	if err != nil {
+		return err
+	}
+
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	// the session of the access token, or the one of the given refresh token
+	sessionId, _ := ctx.Value(types.SessionIdKey("session-id")).(string)
+
+	if req.RefreshToken != "" {
+		claims, err := libs.ParseToken(req.RefreshToken, s.keys, libs.RefreshToken)
+
+		if err != nil {
+			return types.ErrInvalidToken
+		}
+
+		var familyId uuid.UUID
+
+		err = conn.QueryRow(ctx, "SELECT family_id FROM refresh_tokens WHERE id = $1 AND user_id = $2", claims.ID, uuidUserId).Scan(&familyId)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return types.ErrInvalidToken
+		}
+
+		if err != nil {
+			return err
+		}
+
+		sessionId = familyId.String()
+	}
+
+	uuidSessionId, err := uuid.Parse(sessionId)
+
+	// tokens issued before sessions existed carry no session
+	if err != nil {
+		return nil
+	}
+
+	// only the owner of the session may end it
+	ended, err := s.endSession(ctx, conn, uuidUserId, uuidSessionId)
+
+	if err != nil || !ended {
+		return err
+	}
+
+	return s.revokeSessionTokens(ctx, uuidSessionId)
+}
+
+// LogoutAll revokes every token issued to the current user before the given
+// time, personal access tokens included
+func (s *AuthStore) LogoutAll(ctx context.Context, req types.LogoutAllRequestBody) error {
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	before := time.Now()
+
+	// a cutoff in the future would also lock out tokens issued after this call
+	if req.Before != nil && req.Before.Before(before) {
+		before = *req.Before
+	}
+
+	err = s.denylist.RevokeUser(ctx, uuidUserId.String(), before)
+
+	if err != nil {
+		return err
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	prepareQuery := "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND created_at < $2 AND revoked_at IS NULL"
+
+	_, err = conn.Exec(ctx, prepareQuery, uuidUserId, before)
+
+	if err != nil {
+		return err
+	}
+
+	err = deleteUserTokens(ctx, conn, uuidUserId, before)
+
+	if err != nil {
+		return err
+	}
+
+	return s.endOrphanedSessions(ctx, conn, uuidUserId)
+}
+
+// IssueVerificationToken signs a token proving ownership of the user's email
+func (s *AuthStore) IssueVerificationToken(user *types.User) (string, error) {
+	return libs.GenerateToken(libs.CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.Id.String()},
+		Type:             libs.VerifyEmailToken,
+		Email:            user.Email,
+	}, s.keys)
+}
+
+// GetUnverifiedUser returns the user owning the email, or nil when there is
+// no such user or the email is already verified
+func (s *AuthStore) GetUnverifiedUser(ctx context.Context, email string) (*types.User, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	var user types.User
+
+	prepareQuery := "SELECT id, email, created_at, updated_at FROM users WHERE email = $1 AND verified_at IS NULL"
+
+	err = conn.QueryRow(ctx, prepareQuery, email).Scan(&user.Id, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Verify marks the email of the token as verified and returns the id of its
+// user, each token works once
+func (s *AuthStore) Verify(ctx context.Context, req types.VerifyRequestBody) (uuid.UUID, error) {
+	claims, err := libs.ParseToken(req.Token, s.keys, libs.VerifyEmailToken)
+
+	if err != nil {
+		return uuid.Nil, types.ErrInvalidToken
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+
+	if err != nil {
+		return uuid.Nil, types.ErrInvalidToken
+	}
+
+	revoked, err := s.denylist.IsRevoked(ctx, claims.ID)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if revoked {
+		return uuid.Nil, types.ErrInvalidToken
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	// the email must still match, a token for an old address is useless
+	prepareQuery := "UPDATE users SET verified_at = CURRENT_TIMESTAMP WHERE id = $1 AND email = $2 AND verified_at IS NULL"
+
+	tag, err := conn.Exec(ctx, prepareQuery, userId, claims.Email)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return uuid.Nil, types.ErrInvalidToken
+	}
+
+	// burn the token
+	err = s.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userId, nil
+}
+
+// CreatePasswordReset stores a new reset token for the user owning the email.
+// It returns a nil user when the email is unknown.
+func (s *AuthStore) CreatePasswordReset(ctx context.Context, email string, ttl time.Duration) (*types.User, string, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	var user types.User
+
+	prepareQuery := "SELECT id, email FROM users WHERE email = $1"
+
+	err = conn.QueryRow(ctx, prepareQuery, email).Scan(&user.Id, &user.Email)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := libs.RandomToken(32)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	prepareQuery = "INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')"
+
+	_, err = conn.Exec(ctx, prepareQuery, user.Id, libs.HashToken(token), int(ttl.Seconds()))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &user, token, nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and revokes
+// every session and personal access token of the user, it returns the id of
+// the user
+func (s *AuthStore) ResetPassword(ctx context.Context, req types.ResetPasswordRequestBody) (uuid.UUID, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var userId uuid.UUID
+
+	prepareQuery := "SELECT user_id FROM password_reset_tokens WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP FOR UPDATE"
+
+	err = tx.QueryRow(ctx, prepareQuery, libs.HashToken(req.Token)).Scan(&userId)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, types.ErrInvalidToken
+	}
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// hash the password
+	hashedPassword, err := s.hasher.Hash(req.Password)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", hashedPassword, userId)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// the used token and any other outstanding one stop working
+	_, err = tx.Exec(ctx, "UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL", userId)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", userId)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = s.endOrphanedSessions(ctx, tx, userId)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = deleteUserTokens(ctx, tx, userId, time.Now())
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return uuid.Nil, err
+	}
+
+	// access tokens issued before the reset stop working as well
+	err = s.denylist.RevokeUser(ctx, userId.String(), time.Now())
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userId, nil
+}
+
+// issueTokens signs an access/refresh pair for the user and records the
+// refresh token under the given family
+func (s *AuthStore) issueTokens(ctx context.Context, db executor, user types.User, familyId uuid.UUID) (*types.Token, error) {
+	// disabled users keep no way to get new tokens
+	if user.DisabledAt != nil {
+		return nil, types.ErrAccountDisabled
+	}
+
+	// the refresh token family doubles as the session id
+	err := s.touchSession(ctx, db, user.Id, familyId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := libs.CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.Id.String()},
+		SessionId:        familyId.String(),
+		Roles:            []string{user.Role},
+		EmailVerified:    user.VerifiedAt != nil,
+	}
+
 	// generate access token
-	at, err := libs.GenerateToken(user, s.secret, libs.AccessToken)
+	claims.Type = libs.AccessToken
+
+	at, err := libs.GenerateToken(claims, s.keys)
 
 	if err != nil {
 		return nil, err
 	}
 
 	// generate refresh token
-	rt, err := libs.GenerateToken(user, s.secret, libs.RefreshToken)
+	refreshId := uuid.New()
+
+	claims.ID = refreshId.String()
+	claims.Type = libs.RefreshToken
+
+	rt, err := libs.GenerateToken(claims, s.keys)
 
 	if err != nil {
 		return nil, err
 	}
 
-	user.Token.AccessToken = at
-	user.Token.RefreshToken = rt
+	prepareQuery := "INSERT INTO refresh_tokens (id, user_id, family_id, expires_at) VALUES ($1, $2, $3, $4)"
 
-	return &user, nil
+	_, err = db.Exec(ctx, prepareQuery, refreshId, user.Id, familyId, time.Now().Add(libs.TokenTTL(libs.RefreshToken)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Token{
+		AccessToken:  at,
+		RefreshToken: rt,
+	}, nil
 }
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+type ChecklistStore struct {
+	db    *pgxpool.Pool
+	redis *redis.Client
+}
+
+func NewChecklistStore(db *pgxpool.Pool, redis *redis.Client) *ChecklistStore {
+	return &ChecklistStore{
+		db:    db,
+		redis: redis,
+	}
+}
+
+// queryer is an executor that also reads rows
+type queryer interface {
+	executor
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+const checklistItemColumns = "id, title, done, position, created_at, updated_at"
+
+func scanChecklistItem(row pgx.Row, item *types.ChecklistItem) error {
+	return row.Scan(&item.Id, &item.Title, &item.Done, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+}
+
+func (s *ChecklistStore) Get(ctx context.Context, todoId uuid.UUID) (*types.Checklist, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return getChecklist(ctx, conn, todoId, workspaceId)
+}
+
+// Add appends an item to the checklist of a todo
+func (s *ChecklistStore) Add(ctx context.Context, todoId uuid.UUID, req types.ChecklistItemPostRequestBody) (*types.Checklist, error) {
+	return s.change(ctx, todoId, func(tx pgx.Tx) error {
+		prepareQuery := `INSERT INTO checklist_items (todo_id, title, position)
+			VALUES ($1, $2, COALESCE((SELECT max(position) + 1 FROM checklist_items WHERE todo_id = $1), 0))`
+
+		_, err := tx.Exec(ctx, prepareQuery, todoId, req.Title)
+
+		return err
+	})
+}
+
+// Update renames or toggles an item, missing fields keep their value
+func (s *ChecklistStore) Update(ctx context.Context, todoId uuid.UUID, id uuid.UUID, req types.ChecklistItemPatchRequestBody) (*types.Checklist, error) {
+	return s.change(ctx, todoId, func(tx pgx.Tx) error {
+		prepareQuery := "UPDATE checklist_items SET title = COALESCE($1, title), done = COALESCE($2, done) WHERE id = $3 AND todo_id = $4"
+
+		tag, err := tx.Exec(ctx, prepareQuery, req.Title, req.Done, id, todoId)
+
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return types.ErrChecklistItemNotFound
+		}
+
+		return nil
+	})
+}
+
+// Reorder gives the items the order of ids, which must list every item once
+func (s *ChecklistStore) Reorder(ctx context.Context, todoId uuid.UUID, ids []uuid.UUID) (*types.Checklist, error) {
+	return s.change(ctx, todoId, func(tx pgx.Tx) error {
+		var total, listed int
+
+		// the items are locked by the todo, the count can not change under us
+		prepareQuery := "SELECT count(*), count(*) FILTER (WHERE id = ANY($2::uuid[])) FROM checklist_items WHERE todo_id = $1"
+
+		err := tx.QueryRow(ctx, prepareQuery, todoId, ids).Scan(&total, &listed)
+
+		if err != nil {
+			return err
+		}
+
+		// ids are unique, a repeated or unknown one leaves an item unlisted
+		if total != len(ids) || listed != len(ids) {
+			return types.ErrInvalidChecklistOrder
+		}
+
+		prepareQuery = `UPDATE checklist_items c SET position = o.position - 1
+			FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position) WHERE c.id = o.id AND c.todo_id = $1`
+
+		_, err = tx.Exec(ctx, prepareQuery, todoId, ids)
+
+		return err
+	})
+}
+
+func (s *ChecklistStore) Delete(ctx context.Context, todoId uuid.UUID, id uuid.UUID) (*types.Checklist, error) {
+	return s.change(ctx, todoId, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "DELETE FROM checklist_items WHERE id = $1 AND todo_id = $2", id, todoId)
+
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return types.ErrChecklistItemNotFound
+		}
+
+		return nil
+	})
+}
+
+// change applies fn to the checklist of a todo of the workspace. The todo is
+// locked while it runs, its completion then follows the items when
+// auto_complete is set.
+func (s *ChecklistStore) change(ctx context.Context, todoId uuid.UUID, fn func(tx pgx.Tx) error) (*types.Checklist, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// concurrent changes of the same checklist are serialized by the todo
+	var id uuid.UUID
+
+	err = tx.QueryRow(ctx, "SELECT id FROM todos WHERE id = $1 AND workspace_id = $2 FOR UPDATE", todoId, workspaceId).Scan(&id)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTodoNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = fn(tx); err != nil {
+		return nil, err
+	}
+
+	err = syncChecklistCompletion(ctx, tx, todoId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	checklist, err := getChecklist(ctx, tx, todoId, workspaceId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// the todo list shows the progress
+	err = deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checklist, nil
+}
+
+// getChecklist reads the checklist of a todo of the workspace
+func getChecklist(ctx context.Context, db queryer, todoId uuid.UUID, workspaceId uuid.UUID) (*types.Checklist, error) {
+	checklist := types.Checklist{TodoId: todoId, Items: []types.ChecklistItem{}}
+
+	err := db.QueryRow(ctx, "SELECT completed, auto_complete FROM todos WHERE id = $1 AND workspace_id = $2", todoId, workspaceId).Scan(&checklist.Completed, &checklist.AutoComplete)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTodoNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, "SELECT "+checklistItemColumns+" FROM checklist_items WHERE todo_id = $1 ORDER BY position, created_at, id", todoId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var item types.ChecklistItem
+
+		err = scanChecklistItem(rows, &item)
+
+		if err != nil {
+			return nil, err
+		}
+
+		checklist.Items = append(checklist.Items, item)
+
+		if item.Done {
+			checklist.Progress.Done++
+		}
+	}
+
+	checklist.Progress.Total = len(checklist.Items)
+
+	return &checklist, rows.Err()
+}
+
+// syncChecklistCompletion completes an auto_complete todo when every item of
+// its checklist is done and reopens it otherwise, a todo without items is left
+// as it is
+func syncChecklistCompletion(ctx context.Context, db executor, todoId uuid.UUID) error {
+	prepareQuery := `UPDATE todos SET completed = c.done
+		FROM (SELECT bool_and(done) AS done FROM checklist_items WHERE todo_id = $1) c
+		WHERE id = $1 AND auto_complete AND c.done IS NOT NULL AND completed <> c.done`
+
+	_, err := db.Exec(ctx, prepareQuery, todoId)
+
+	return err
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+// todosSortTypes is the sql type of every sortable column, cursor values are
+// cast to it
+var todosSortTypes = map[string]string{
+	"title":      "text",
+	"completed":  "boolean",
+	"due_date":   "timestamp",
+	"created_at": "timestamp",
+	"updated_at": "timestamp",
+}
+
+// todosCursor is the position of a todo in the order of a sort, it is handed
+// to clients base64 encoded
+type todosCursor struct {
+	// the sort the cursor was issued for
+	Sort string `json:"s"`
+	// the sort column values of the todo, nil for NULL
+	Values []*string `json:"v"`
+	Id     uuid.UUID `json:"id"`
+	// the page is the one before the todo rather than after it
+	Prev bool `json:"p,omitempty"`
+}
+
+// newTodosCursor returns the cursor of todo in the order of sort
+func newTodosCursor(todo types.Todos, sort []types.TodosSort, prev bool) string {
+	cursor := todosCursor{Sort: sortKey(sort), Id: todo.Id, Prev: prev}
+
+	for _, field := range sort {
+		cursor.Values = append(cursor.Values, todoSortValue(todo, field.Column))
+	}
+
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTodosCursor reads a cursor, it must have been issued for the same sort
+func decodeTodosCursor(s string, sort []types.TodosSort) (*todosCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return nil, types.ErrInvalidCursor
+	}
+
+	var cursor todosCursor
+
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, types.ErrInvalidCursor
+	}
+
+	if cursor.Sort != sortKey(sort) || len(cursor.Values) != len(sort) {
+		return nil, fmt.Errorf("%w: the sort has changed", types.ErrInvalidCursor)
+	}
+
+	// values are cast in the query, a tampered value must not reach it
+	for i, value := range cursor.Values {
+		if value != nil && !validSortValue(todosSortTypes[sort[i].Column], *value) {
+			return nil, types.ErrInvalidCursor
+		}
+	}
+
+	return &cursor, nil
+}
+
+func validSortValue(sqlType string, value string) bool {
+	var err error
+
+	switch sqlType {
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	case "timestamp":
+		_, err = time.Parse(timestampLayout, value)
+	}
+
+	return err == nil
+}
+
+// sortKey is the canonical form of a sort, as accepted by types.ParseTodosSort
+func sortKey(sort []types.TodosSort) string {
+	names := make([]string, len(sort))
+
+	for i, field := range sort {
+		names[i] = field.Column
+
+		if field.Desc {
+			names[i] = "-" + field.Column
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// todoSortValue returns the value of a sortable column as its sql literal
+func todoSortValue(todo types.Todos, column string) *string {
+	var value string
+
+	switch column {
+	case "title":
+		value = todo.Title
+	case "completed":
+		value = strconv.FormatBool(todo.Completed)
+	case "due_date":
+		if todo.DueDate == nil {
+			return nil
+		}
+
+		value = formatTimestamp(*todo.DueDate)
+	case "created_at":
+		value = formatTimestamp(todo.CreatedAt)
+	case "updated_at":
+		value = formatTimestamp(todo.UpdatedAt)
+	}
+
+	return &value
+}
+
+// a timestamp without time zone at the precision of postgres
+const timestampLayout = "2006-01-02T15:04:05.999999"
+
+// formatTimestamp formats a time read from a column without time zone
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// after adds the condition of the todos after the cursor in the order of sort,
+// rows are compared column by column and the id breaks ties. Nulls sort last,
+// or first when reverse.
+func (f *todosFilter) after(sort []types.TodosSort, cursor *todosCursor, reverse bool) {
+	var or []string
+	// equality of every column before the current one
+	var equal []string
+
+	for i, field := range sort {
+		column := field.Column
+		value := cursor.Values[i]
+		desc := field.Desc != reverse
+
+		var greater, same string
+
+		if value == nil {
+			same = column + " IS NULL"
+			// nulls are last, only the reverse order has rows after them
+			greater = "FALSE"
+
+			if reverse {
+				greater = column + " IS NOT NULL"
+			}
+		} else {
+			placeholder := fmt.Sprintf("%s::%s", f.arg(*value), todosSortTypes[column])
+			same = column + " = " + placeholder
+			op := ">"
+
+			if desc {
+				op = "<"
+			}
+
+			greater = fmt.Sprintf("%s %s %s", column, op, placeholder)
+
+			if !reverse {
+				greater = fmt.Sprintf("(%s OR %s IS NULL)", greater, column)
+			}
+		}
+
+		or = append(or, strings.Join(append(equal, greater), " AND "))
+		equal = append(equal, same)
+	}
+
+	op := ">"
+
+	if reverse {
+		op = "<"
+	}
+
+	or = append(or, strings.Join(append(equal, "id "+op+" "+f.arg(cursor.Id)), " AND "))
+
+	f.where = append(f.where, "(("+strings.Join(or, ") OR (")+"))")
+}
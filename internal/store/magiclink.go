@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// CreateMagicLink stores a new sign-in token for the email, the account is
+// only looked up or created once the link is used
+func (s *AuthStore) CreateMagicLink(ctx context.Context, email string, ttl time.Duration) (string, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	token, err := libs.RandomToken(32)
+
+	if err != nil {
+		return "", err
+	}
+
+	prepareQuery := "INSERT INTO magic_link_tokens (email, token_hash, expires_at) VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')"
+
+	_, err = conn.Exec(ctx, prepareQuery, strings.TrimSpace(email), libs.HashToken(token), int(ttl.Seconds()))
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeMagicLink exchanges a sign-in token for a login. An unknown email
+// gets a new account without a password, opening the link proves ownership
+// of the email so the account is verified. An unverified account is claimed,
+// see claimAccount.
+func (s *AuthStore) ConsumeMagicLink(ctx context.Context, req types.MagicLinkConsumeRequestBody) (*types.User, error) {
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var email string
+
+	prepareQuery := "SELECT email FROM magic_link_tokens WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP FOR UPDATE"
+
+	err = tx.QueryRow(ctx, prepareQuery, libs.HashToken(req.Token)).Scan(&email)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the used link and any other outstanding one for the email stop working
+	_, err = tx.Exec(ctx, "UPDATE magic_link_tokens SET used_at = CURRENT_TIMESTAMP WHERE email = $1 AND used_at IS NULL", email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var user types.User
+	var totpEnabledAt *time.Time
+	// sessions of an unverified registrant, ended when the account is claimed
+	var ended []uuid.UUID
+
+	prepareQuery = "SELECT id, email, verified_at, role, disabled_at, created_at, updated_at, totp_enabled_at FROM users WHERE email = $1 FOR UPDATE"
+
+	err = tx.QueryRow(ctx, prepareQuery, email).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt, &totpEnabledAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		prepareQuery = "INSERT INTO users (email, verified_at) VALUES ($1, CURRENT_TIMESTAMP) RETURNING id, email, verified_at, role, created_at, updated_at"
+
+		err = tx.QueryRow(ctx, prepareQuery, email).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+
+		if err == nil {
+			err = createPersonalWorkspace(ctx, tx, user.Id)
+		}
+	} else if err == nil && user.VerifiedAt == nil {
+		ended, err = s.claimAccount(ctx, tx, &user)
+		totpEnabledAt = nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// 2fa of a verified account still applies, the link only replaces the password
+	res, err := s.completeLogin(ctx, tx, user, totpEnabledAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, sessionId := range ended {
+		if err = s.revokeSessionTokens(ctx, sessionId); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,392 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+const (
+	totpIssuer        = "TodoApp"
+	recoveryCodeCount = 10
+)
+
+type totpState struct {
+	secret    *string
+	enabledAt *time.Time
+	lastStep  *int64
+}
+
+// LoginMfa exchanges the challenge returned by Login plus a second factor
+// for a token pair
+func (s *AuthStore) LoginMfa(ctx context.Context, req types.MfaLoginRequestBody) (*types.User, error) {
+	claims, err := libs.ParseToken(req.MfaToken, s.keys, libs.MfaPendingToken)
+
+	if err != nil {
+		return nil, types.ErrInvalidToken
+	}
+
+	revoked, err := s.denylist.IsRevoked(ctx, claims.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, types.ErrInvalidToken
+	}
+
+	// a challenge is good for a single attempt, guessing codes needs the password every time
+	err = s.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var user types.User
+	var state totpState
+
+	prepareQuery := "SELECT id, email, verified_at, role, disabled_at, created_at, updated_at, totp_secret, totp_enabled_at, totp_last_step FROM users WHERE id = $1 FOR UPDATE"
+
+	err = tx.QueryRow(ctx, prepareQuery, claims.Subject).Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt, &state.secret, &state.enabledAt, &state.lastStep)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// 2fa was disabled while the challenge was pending
+	if state.enabledAt == nil {
+		return nil, types.ErrInvalidToken
+	}
+
+	ok, err := checkSecondFactor(ctx, tx, user.Id, state, req.Code, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &types.InvalidCodeError{Email: user.Email}
+	}
+
+	token, err := s.issueTokens(ctx, tx, user, uuid.New())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	user.Token = *token
+
+	return &user, nil
+}
+
+// EnrollTotp generates a new secret for the current user, it is only enforced
+// once confirmed with a valid code
+func (s *AuthStore) EnrollTotp(ctx context.Context) (*types.TotpEnrollment, error) {
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	var email string
+	var enabledAt *time.Time
+
+	err = conn.QueryRow(ctx, "SELECT email, totp_enabled_at FROM users WHERE id = $1", uuidUserId).Scan(&email, &enabledAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledAt != nil {
+		return nil, types.ErrMfaEnabled
+	}
+
+	secret, err := libs.GenerateTOTPSecret()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.Exec(ctx, "UPDATE users SET totp_secret = $1, totp_last_step = NULL WHERE id = $2", secret, uuidUserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TotpEnrollment{
+		Secret: secret,
+		Uri:    libs.TOTPURI(totpIssuer, email, secret),
+	}, nil
+}
+
+// ConfirmTotp enables 2fa once the user proves the authenticator works
+func (s *AuthStore) ConfirmTotp(ctx context.Context, req types.TotpCodeRequestBody) (*types.RecoveryCodes, error) {
+	var codes []string
+
+	err := s.withTotpState(ctx, func(tx pgx.Tx, userId uuid.UUID, state totpState) error {
+		if state.enabledAt != nil {
+			return types.ErrMfaEnabled
+		}
+
+		if state.secret == nil {
+			return types.ErrMfaNotEnrolled
+		}
+
+		// recovery codes do not exist yet
+		ok, err := checkSecondFactor(ctx, tx, userId, state, req.Code, false)
+
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return types.ErrInvalidCode
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE users SET totp_enabled_at = CURRENT_TIMESTAMP WHERE id = $1", userId)
+
+		if err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, tx, userId)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTotp turns 2fa off, it needs a valid totp or recovery code
+func (s *AuthStore) DisableTotp(ctx context.Context, req types.TotpCodeRequestBody) error {
+	return s.withTotpState(ctx, func(tx pgx.Tx, userId uuid.UUID, state totpState) error {
+		if state.enabledAt == nil {
+			return types.ErrMfaNotEnabled
+		}
+
+		ok, err := checkSecondFactor(ctx, tx, userId, state, req.Code, true)
+
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return types.ErrInvalidCode
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = $1", userId)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userId)
+
+		return err
+	})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the current user
+func (s *AuthStore) RegenerateRecoveryCodes(ctx context.Context, req types.TotpCodeRequestBody) (*types.RecoveryCodes, error) {
+	var codes []string
+
+	err := s.withTotpState(ctx, func(tx pgx.Tx, userId uuid.UUID, state totpState) error {
+		if state.enabledAt == nil {
+			return types.ErrMfaNotEnabled
+		}
+
+		ok, err := checkSecondFactor(ctx, tx, userId, state, req.Code, true)
+
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return types.ErrInvalidCode
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, tx, userId)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RecoveryCodes{Codes: codes}, nil
+}
+
+// withTotpState runs fn in a transaction holding a lock on the current user's 2fa state
+func (s *AuthStore) withTotpState(ctx context.Context, fn func(tx pgx.Tx, userId uuid.UUID, state totpState) error) error {
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	// Acquire a connection from the pool
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// Release the connection back to the pool
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var state totpState
+
+	prepareQuery := "SELECT totp_secret, totp_enabled_at, totp_last_step FROM users WHERE id = $1 FOR UPDATE"
+
+	err = tx.QueryRow(ctx, prepareQuery, uuidUserId).Scan(&state.secret, &state.enabledAt, &state.lastStep)
+
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx, uuidUserId, state)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// checkSecondFactor accepts a totp code newer than the last one used or,
+// when allowed, an unused recovery code. Accepted codes are consumed.
+func checkSecondFactor(ctx context.Context, tx pgx.Tx, userId uuid.UUID, state totpState, code string, allowRecovery bool) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if state.secret == nil || code == "" {
+		return false, nil
+	}
+
+	if step, ok := libs.ValidateTOTP(*state.secret, code, time.Now()); ok {
+		// the same code can not be used twice
+		if state.lastStep != nil && step <= *state.lastStep {
+			return false, nil
+		}
+
+		_, err := tx.Exec(ctx, "UPDATE users SET totp_last_step = $1 WHERE id = $2", step, userId)
+
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+
+	prepareQuery := "UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE id = (SELECT id FROM recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)"
+
+	tag, err := tx.Exec(ctx, prepareQuery, userId, libs.HashToken(normalizeRecoveryCode(code)))
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// replaceRecoveryCodes drops the user's recovery codes and returns fresh ones,
+// only their hashes are stored
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userId uuid.UUID) ([]string, error) {
+	_, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for i := range codes {
+		b := make([]byte, 5)
+
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		// 8 characters shown as xxxx-xxxx
+		raw := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = raw[:4] + "-" + raw[4:]
+
+		_, err = tx.Exec(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userId, libs.HashToken(raw))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+	"github.com/redis/go-redis/v9"
+)
+
+// OidcState is what a login needs to remember between the redirect and the callback
+type OidcState struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	// ties the login to the user agent that started it
+	Binding string `json:"binding"`
+}
+
+type OidcStore struct {
+	redis *redis.Client
+}
+
+func NewOidcStore(redis *redis.Client) *OidcStore {
+	return &OidcStore{
+		redis: redis,
+	}
+}
+
+func oidcStateKey(state string) string {
+	return "oidc:state:" + state
+}
+
+func (s *OidcStore) SaveState(ctx context.Context, state string, data OidcState, ttl time.Duration) error {
+	value, err := libs.StringifyJSON(data)
+
+	if err != nil {
+		return err
+	}
+
+	return s.redis.Set(ctx, oidcStateKey(state), value, ttl).Err()
+}
+
+// TakeState returns and deletes the state so every login can only complete
+// once. The state is only consumed by the user agent holding its binding, a
+// leaked callback url can not sign another browser in.
+func (s *OidcStore) TakeState(ctx context.Context, state string, binding string) (*OidcState, error) {
+	value, err := s.redis.Get(ctx, oidcStateKey(state)).Result()
+
+	if err == redis.Nil {
+		return nil, types.ErrInvalidState
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data OidcState
+
+	err = libs.ParseStringJSON(value, &data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Binding == "" || subtle.ConstantTimeCompare([]byte(data.Binding), []byte(binding)) != 1 {
+		return nil, types.ErrInvalidState
+	}
+
+	// only the first of concurrent callbacks gets the state
+	err = s.redis.GetDel(ctx, oidcStateKey(state)).Err()
+
+	if err == redis.Nil {
+		return nil, types.ErrInvalidState
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+type ProjectsStore struct {
+	db    *pgxpool.Pool
+	redis *redis.Client
+}
+
+func NewProjectsStore(db *pgxpool.Pool, redis *redis.Client) *ProjectsStore {
+	return &ProjectsStore{
+		db:    db,
+		redis: redis,
+	}
+}
+
+const projectColumns = "id, name, color, archived, position, " +
+	"(SELECT count(*) FROM todos WHERE project_id = projects.id), " +
+	"(SELECT count(*) FROM todos WHERE project_id = projects.id AND completed), " +
+	"created_at, updated_at"
+
+func scanProject(row pgx.Row, project *types.Project) error {
+	return row.Scan(&project.Id, &project.Name, &project.Color, &project.Archived, &project.Position, &project.Todos, &project.Completed, &project.CreatedAt, &project.UpdatedAt)
+}
+
+func (s *ProjectsStore) Get(ctx context.Context, query types.ProjectsQuery) ([]types.Project, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prepareQuery := "SELECT " + projectColumns + " FROM projects WHERE workspace_id = $1 AND ($2::boolean IS NULL OR archived = $2) ORDER BY position, created_at, id"
+
+	rows, err := conn.Query(ctx, prepareQuery, workspaceId, query.Archived)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	projects := []types.Project{}
+
+	for rows.Next() {
+		var project types.Project
+
+		err = scanProject(rows, &project)
+
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+func (s *ProjectsStore) Create(ctx context.Context, req types.ProjectPostRequestBody) (*types.Project, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var project types.Project
+
+	// without a position the project goes after the others
+	prepareQuery := `INSERT INTO projects (workspace_id, name, color, position)
+		VALUES ($1, $2, $3, COALESCE($4::integer, (SELECT max(position) + 1 FROM projects WHERE workspace_id = $1), 0))
+		RETURNING ` + projectColumns
+
+	err = scanProject(conn.QueryRow(ctx, prepareQuery, workspaceId, req.Name, req.Color, req.Position), &project)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (s *ProjectsStore) GetById(ctx context.Context, id uuid.UUID) (*types.Project, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var project types.Project
+
+	err = scanProject(conn.QueryRow(ctx, "SELECT "+projectColumns+" FROM projects WHERE id = $1 AND workspace_id = $2", id, workspaceId), &project)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrProjectNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (s *ProjectsStore) Update(ctx context.Context, id uuid.UUID, req types.ProjectPatchRequestBody) (*types.Project, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var project types.Project
+
+	// missing fields keep their value
+	prepareQuery := `UPDATE projects SET name = COALESCE($1, name), color = COALESCE($2, color),
+		archived = COALESCE($3, archived), position = COALESCE($4, position)
+		WHERE id = $5 AND workspace_id = $6 RETURNING ` + projectColumns
+
+	err = scanProject(conn.QueryRow(ctx, prepareQuery, req.Name, req.Color, req.Archived, req.Position, id, workspaceId), &project)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrProjectNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+// Delete removes a project, its todos are moved to the inbox or deleted
+// depending on mode
+func (s *ProjectsStore) Delete(ctx context.Context, id uuid.UUID, mode string) error {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// the todos are handled first, the project can not be deleted while they reference it
+	prepareQuery := "UPDATE todos SET project_id = NULL WHERE project_id = $1 AND workspace_id = $2"
+
+	if mode == types.ProjectDeleteCascade {
+		prepareQuery = "DELETE FROM todos WHERE project_id = $1 AND workspace_id = $2"
+	}
+
+	_, err = tx.Exec(ctx, prepareQuery, id, workspaceId)
+
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM projects WHERE id = $1 AND workspace_id = $2", id, workspaceId)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return types.ErrProjectNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+}
+
+// isProjectViolation tells whether a todo was given a project missing from its workspace
+func isProjectViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == "todos_project_fkey"
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+// GetSessions lists the sessions of the current user that still hold a usable refresh token
+func (s *AuthStore) GetSessions(ctx context.Context) ([]types.Session, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	currentId, _ := ctx.Value(types.SessionIdKey("session-id")).(string)
+
+	prepareQuery := `SELECT id, device, user_agent, ip, created_at, last_seen_at FROM sessions s
+		WHERE user_id = $1 AND revoked_at IS NULL
+		AND EXISTS (SELECT 1 FROM refresh_tokens WHERE family_id = s.id AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP)
+		ORDER BY last_seen_at DESC`
+
+	rows, err := conn.Query(ctx, prepareQuery, uuidUserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := []types.Session{}
+
+	for rows.Next() {
+		var session types.Session
+
+		err = rows.Scan(&session.Id, &session.Device, &session.UserAgent, &session.Ip, &session.CreatedAt, &session.LastSeenAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		session.Current = session.Id.String() == currentId
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession signs the current user out of one of their sessions
+func (s *AuthStore) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	ended, err := s.endSession(ctx, tx, uuidUserId, id)
+
+	if err != nil {
+		return err
+	}
+
+	if !ended {
+		return types.ErrSessionNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return s.revokeSessionTokens(ctx, id)
+}
+
+// touchSession records a login or refresh of the session with the client details of the request
+func (s *AuthStore) touchSession(ctx context.Context, db executor, userId uuid.UUID, sessionId uuid.UUID) error {
+	ip, _ := ctx.Value(types.ClientIpKey("client-ip")).(string)
+	userAgent, _ := ctx.Value(types.UserAgentKey("user-agent")).(string)
+
+	prepareQuery := `INSERT INTO sessions (id, user_id, device, user_agent, ip) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET device = EXCLUDED.device, user_agent = EXCLUDED.user_agent, ip = EXCLUDED.ip, last_seen_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(ctx, prepareQuery, sessionId, userId, libs.DeviceName(userAgent), userAgent, ip)
+
+	return err
+}
+
+// endSession revokes the session and its refresh token family, it reports false
+// when the user has no such active session
+func (s *AuthStore) endSession(ctx context.Context, db executor, userId uuid.UUID, sessionId uuid.UUID) (bool, error) {
+	tag, err := db.Exec(ctx, "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL", sessionId, userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = db.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL", sessionId, userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// endOrphanedSessions revokes the sessions of the user left without an active refresh token
+func (s *AuthStore) endOrphanedSessions(ctx context.Context, db executor, userId uuid.UUID) error {
+	prepareQuery := `UPDATE sessions s SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM refresh_tokens WHERE family_id = s.id AND revoked_at IS NULL)`
+
+	_, err := db.Exec(ctx, prepareQuery, userId)
+
+	return err
+}
+
+// revokeSessionTokens denies the access tokens already issued to a session,
+// the session id is kept until the last of them expires
+func (s *AuthStore) revokeSessionTokens(ctx context.Context, sessionId uuid.UUID) error {
+	return s.denylist.Revoke(ctx, sessionId.String(), time.Now().Add(libs.TokenTTL(libs.AccessToken)))
+}
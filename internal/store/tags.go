@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+type TagsStore struct {
+	db    *pgxpool.Pool
+	redis *redis.Client
+}
+
+func NewTagsStore(db *pgxpool.Pool, redis *redis.Client) *TagsStore {
+	return &TagsStore{
+		db:    db,
+		redis: redis,
+	}
+}
+
+const tagColumns = "id, name, color, (SELECT count(*) FROM todo_tags WHERE tag_id = tags.id), created_at, updated_at"
+
+func scanTag(row pgx.Row, tag *types.Tag) error {
+	return row.Scan(&tag.Id, &tag.Name, &tag.Color, &tag.Todos, &tag.CreatedAt, &tag.UpdatedAt)
+}
+
+func (s *TagsStore) Get(ctx context.Context) ([]types.Tag, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, "SELECT "+tagColumns+" FROM tags WHERE workspace_id = $1 ORDER BY lower(name)", workspaceId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := []types.Tag{}
+
+	for rows.Next() {
+		var tag types.Tag
+
+		err = scanTag(rows, &tag)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (s *TagsStore) Create(ctx context.Context, req types.TagPostRequestBody) (*types.Tag, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tag types.Tag
+
+	prepareQuery := "INSERT INTO tags (workspace_id, name, color) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING RETURNING " + tagColumns
+
+	err = scanTag(conn.QueryRow(ctx, prepareQuery, workspaceId, req.Name, req.Color), &tag)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTagExists
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (s *TagsStore) GetById(ctx context.Context, id uuid.UUID) (*types.Tag, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tag types.Tag
+
+	err = scanTag(conn.QueryRow(ctx, "SELECT "+tagColumns+" FROM tags WHERE id = $1 AND workspace_id = $2", id, workspaceId), &tag)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTagNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// Update renames or recolors a tag, the todos of the workspace show the change
+func (s *TagsStore) Update(ctx context.Context, id uuid.UUID, req types.TagPatchRequestBody) (*types.Tag, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tag types.Tag
+
+	// missing fields keep their value
+	prepareQuery := "UPDATE tags SET name = COALESCE($1, name), color = COALESCE($2, color) WHERE id = $3 AND workspace_id = $4 RETURNING " + tagColumns
+
+	err = scanTag(conn.QueryRow(ctx, prepareQuery, req.Name, req.Color, id, workspaceId), &tag)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTagNotFound
+	}
+
+	if isUniqueViolation(err) {
+		return nil, types.ErrTagExists
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// cached todos still carry the old name
+	err = deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// Delete removes a tag from the workspace and from every todo
+func (s *TagsStore) Delete(ctx context.Context, id uuid.UUID) error {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return err
+	}
+
+	tag, err := conn.Exec(ctx, "DELETE FROM tags WHERE id = $1 AND workspace_id = $2", id, workspaceId)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return types.ErrTagNotFound
+	}
+
+	return deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+}
+
+// setTodoTags replaces the tags of a todo, tags missing from the workspace are
+// created with the default color
+func setTodoTags(ctx context.Context, tx pgx.Tx, workspaceId uuid.UUID, todoId uuid.UUID, names []string) error {
+	_, err := tx.Exec(ctx, "DELETE FROM todo_tags WHERE todo_id = $1", todoId)
+
+	if err != nil || len(names) == 0 {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO tags (workspace_id, name) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING", workspaceId, names)
+
+	if err != nil {
+		return err
+	}
+
+	prepareQuery := "INSERT INTO todo_tags (todo_id, tag_id) SELECT $1, id FROM tags WHERE workspace_id = $2 AND lower(name) = ANY(SELECT lower(unnest($3::text[])))"
+
+	_, err = tx.Exec(ctx, prepareQuery, todoId, workspaceId, names)
+
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
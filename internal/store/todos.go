@@ -2,9 +2,14 @@ package store
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/odev-swe/todoapp/internal/types"
 	"github.com/odev-swe/todoapp/libs"
@@ -24,6 +29,37 @@ func NewTodosStore(db *pgxpool.Pool, redis *redis.Client) *TodosStore {
 	}
 }
 
+// todoColumns reads the tag names and the checklist progress of a todo with
+// its columns, a statement does not see the tags it sets so writes select the
+// todo again
+const todoColumns = "id, title, description, completed, due_date, project_id, " +
+	"ARRAY(SELECT t.name FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.todo_id = todos.id ORDER BY lower(t.name)), " +
+	"auto_complete, (SELECT count(*) FILTER (WHERE done) FROM checklist_items WHERE todo_id = todos.id), " +
+	"(SELECT count(*) FROM checklist_items WHERE todo_id = todos.id), created_at, updated_at"
+
+func scanTodo(row pgx.Row, todo *types.Todos) error {
+	return row.Scan(&todo.Id, &todo.Title, &todo.Description, &todo.Completed, &todo.DueDate, &todo.ProjectId, &todo.Tags, &todo.AutoComplete, &todo.Progress.Done, &todo.Progress.Total, &todo.CreatedAt, &todo.UpdatedAt)
+}
+
+// getTodo selects a todo of the workspace
+func getTodo(ctx context.Context, db executor, id uuid.UUID, workspaceId uuid.UUID) (*types.Todos, error) {
+	var todo types.Todos
+
+	prepareQuery := "SELECT " + todoColumns + " FROM todos WHERE id = $1 AND workspace_id = $2"
+
+	err := scanTodo(db.QueryRow(ctx, prepareQuery, id, workspaceId), &todo)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTodoNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
 func (s *TodosStore) Create(ctx context.Context, req types.TodosPostRequestBody) (*types.Todos, error) {
 	// acquire connection
 	conn, err := s.db.Acquire(ctx)
@@ -42,69 +78,124 @@ func (s *TodosStore) Create(ctx context.Context, req types.TodosPostRequestBody)
 		return nil, err
 	}
 
+	workspaceId, err := resolveWorkspace(ctx, conn, uuidUserId, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the todo and its tags are written together
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
 	// perform query
-	var todo types.Todos
+	var id uuid.UUID
+
+	prepareQuery := "INSERT INTO todos (title, description, completed, due_date, project_id, auto_complete, user_id, workspace_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
 
-	prepareQuery := "INSERT INTO todos (title, description, completed, due_date, user_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, title, description, completed, due_date, created_at, updated_at"
+	err = tx.QueryRow(ctx, prepareQuery, req.Title, req.Description, req.Completed, req.DueDate, req.ProjectId, req.AutoComplete, uuidUserId, workspaceId).Scan(&id)
 
-	err = conn.QueryRow(ctx, prepareQuery, req.Title, req.Description, req.Completed, req.DueDate, uuidUserId).Scan(&todo.Id, &todo.Title, &todo.Description, &todo.Completed, &todo.DueDate, &todo.CreatedAt, &todo.UpdatedAt)
+	if isProjectViolation(err) {
+		return nil, types.ErrInvalidProject
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = deleteCache(ctx, "todos", s.redis)
+	err = setTodoTags(ctx, tx, workspaceId, id, req.Tags)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &todo, nil
+	todo, err := getTodo(ctx, tx, id, workspaceId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	err = deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
 }
 
-func (s *TodosStore) Get(ctx context.Context) ([]types.Todos, error) {
-	var todo types.Todos
-	var todos []types.Todos
+func (s *TodosStore) Get(ctx context.Context, query types.TodosQuery) (*types.TodosPage, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
 
-	// check cache first
-	jsonData, err := s.redis.Get(ctx, "todos").Result()
+	if err != nil {
+		return nil, err
+	}
 
-	if err == redis.Nil {
-		// acquire connection
-		conn, err := s.db.Acquire(ctx)
+	// defer release connection
+	defer conn.Release()
 
-		if err != nil {
-			return nil, err
-		}
+	// membership is checked before the cache is read
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
 
-		// defer release connection
-		defer conn.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	sort := query.Sort
+
+	if len(sort) == 0 {
+		sort = types.DefaultTodosSort
+	}
 
-		// get user id from context
-		userId := ctx.Value(types.UserIdKey("user-id"))
-		uuidUserId, err := uuid.Parse(userId.(string))
+	var cursor *todosCursor
 
-		limit := ctx.Value("limit")
-		offset := ctx.Value("offset")
+	if query.Cursor != "" {
+		cursor, err = decodeTodosCursor(query.Cursor, sort)
 
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	// every query of the workspace is a field of the same hash, a write drops them all
+	field, err := libs.StringifyJSON(query)
 
-		// perform query
+	if err != nil {
+		return nil, err
+	}
 
-		// pagination purpose for optimization
-		prepareQuery := "SELECT id, title, description, completed, due_date, created_at, updated_at FROM todos WHERE user_id = $1 LIMIT $2 OFFSET $3"
+	var page types.TodosPage
+
+	// check cache first
+	jsonData, err := s.redis.HGet(ctx, todosCacheKey(workspaceId), string(field)).Result()
+
+	if err == redis.Nil {
+		prepareQuery, args := todosQuerySQL(workspaceId, query, sort, cursor)
 
-		rows, err := conn.Query(ctx, prepareQuery, uuidUserId, limit, offset)
+		rows, err := conn.Query(ctx, prepareQuery, args...)
 
 		if err != nil {
 			return nil, err
 		}
 
+		defer rows.Close()
+
+		todos := []types.Todos{}
+
 		for rows.Next() {
+			var todo types.Todos
 
-			err = rows.Scan(&todo.Id, &todo.Title, &todo.Description, &todo.Completed, &todo.DueDate, &todo.CreatedAt, &todo.UpdatedAt)
+			err = scanTodo(rows, &todo)
 
 			if err != nil {
 				return nil, err
@@ -113,36 +204,100 @@ func (s *TodosStore) Get(ctx context.Context) ([]types.Todos, error) {
 			todos = append(todos, todo)
 		}
 
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+
+		page = todosPage(todos, query.Limit, sort, cursor)
+
+		if query.Total {
+			countQuery, countArgs := todosCountSQL(workspaceId, query)
+
+			var total int
+
+			err = conn.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
+
+			if err != nil {
+				return nil, err
+			}
+
+			page.Total = &total
+		}
+
 		// set cache
-		data, err := libs.StringifyJSON(todos)
+		data, err := libs.StringifyJSON(page)
 
 		if err != nil {
 			return nil, err
 		}
 
-		// expiration in 10 seconds
+		// expiration in 30 seconds
 		zap.L().Info("retrieve data from database")
-		err = s.redis.Set(ctx, "todos", data, 30*time.Second).Err()
+
+		pipe := s.redis.TxPipeline()
+		pipe.HSet(ctx, todosCacheKey(workspaceId), string(field), data)
+		pipe.Expire(ctx, todosCacheKey(workspaceId), 30*time.Second)
+		_, err = pipe.Exec(ctx)
 
 		if err != nil {
 			return nil, err
 		}
 
-		return todos, nil
+		return &page, nil
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
 	zap.L().Info("retrieve data from cache")
 
-	err = libs.ParseStringJSON(jsonData, &todos)
+	err = libs.ParseStringJSON(jsonData, &page)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return todos, nil
+	return &page, nil
+}
+
+// todosPage trims the extra row read past the limit and sets the cursors of
+// the pages around it
+func todosPage(todos []types.Todos, limit int, sort []types.TodosSort, cursor *todosCursor) types.TodosPage {
+	more := len(todos) > limit
+
+	if more {
+		todos = todos[:limit]
+	}
+
+	backward := cursor != nil && cursor.Prev
+
+	// the page before a cursor was read backwards
+	if backward {
+		slices.Reverse(todos)
+	}
+
+	page := types.TodosPage{Todos: todos}
+
+	if len(todos) == 0 {
+		return page
+	}
+
+	// going back from a cursor, the todos after the page are the ones already seen
+	if more || backward {
+		page.NextCursor = newTodosCursor(todos[len(todos)-1], sort, false)
+	}
+
+	if (more && backward) || (cursor != nil && !backward) {
+		page.PrevCursor = newTodosCursor(todos[0], sort, true)
+	}
+
+	return page
 }
 
-func (s *TodosStore) Update(ctx context.Context, req types.TodosPutRequestBody) (*types.Todos, error) {
+// Search returns the todos of the workspace matching the full-text query,
+// results are not cached
+func (s *TodosStore) Search(ctx context.Context, tsQuery string, query types.TodosQuery) ([]types.TodoSearchResult, error) {
 	// acquire connection
 	conn, err := s.db.Acquire(ctx)
 
@@ -152,76 +307,406 @@ func (s *TodosStore) Update(ctx context.Context, req types.TodosPutRequestBody)
 	// defer release connection
 	defer conn.Release()
 
-	// get user id from context
-	userId := ctx.Value(types.UserIdKey("user-id"))
-	uuidUserId, err := uuid.Parse(userId.(string))
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// perform query
-	var todo types.Todos
+	prepareQuery, args := todosSearchSQL(workspaceId, tsQuery, query)
+
+	rows, err := conn.Query(ctx, prepareQuery, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	results := []types.TodoSearchResult{}
+
+	for rows.Next() {
+		var result types.TodoSearchResult
+		todo := &result.Todos
+
+		err = rows.Scan(&todo.Id, &todo.Title, &todo.Description, &todo.Completed, &todo.DueDate, &todo.ProjectId, &todo.Tags, &todo.AutoComplete, &todo.Progress.Done, &todo.Progress.Total, &todo.CreatedAt, &todo.UpdatedAt, &result.Rank, &result.TitleHighlight, &result.DescriptionHighlight)
+
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// todosQuerySQL builds the list query of a workspace. Filter values are
+// always bound as arguments and sort columns come from a whitelist. One row
+// more than the limit is read to tell whether another page follows.
+func todosQuerySQL(workspaceId uuid.UUID, query types.TodosQuery, sort []types.TodosSort, cursor *todosCursor) (string, []any) {
+	f := newTodosFilter(workspaceId, query)
+
+	if query.Q != "" {
+		f.add("title ILIKE '%' || ? || '%'", escapeLike(query.Q))
+	}
+
+	// the page before a cursor is read backwards
+	reverse := cursor != nil && cursor.Prev
+
+	if cursor != nil {
+		f.after(sort, cursor, reverse)
+	}
+
+	prepareQuery := "SELECT " + todoColumns + " FROM todos WHERE " + strings.Join(f.where, " AND ") + " ORDER BY " + strings.Join(todosOrder(sort, reverse), ", ")
+
+	return prepareQuery + " LIMIT " + f.arg(query.Limit+1), f.args
+}
+
+// todosCountSQL counts the todos matching the filters of the list
+func todosCountSQL(workspaceId uuid.UUID, query types.TodosQuery) (string, []any) {
+	f := newTodosFilter(workspaceId, query)
+
+	if query.Q != "" {
+		f.add("title ILIKE '%' || ? || '%'", escapeLike(query.Q))
+	}
+
+	return "SELECT count(*) FROM todos WHERE " + strings.Join(f.where, " AND "), f.args
+}
+
+// todosSearchSQL builds the full-text search query of a workspace, matches are
+// ranked unless a sort is given
+func todosSearchSQL(workspaceId uuid.UUID, tsQuery string, query types.TodosQuery) (string, []any) {
+	f := newTodosFilter(workspaceId, query)
+	match := fmt.Sprintf("to_tsquery('simple', %s)", f.arg(tsQuery))
 
-	prepareQuery := "UPDATE todos SET title = $1, description = $2, completed = $3, due_date = $4 WHERE id = $5 AND user_id = $6 RETURNING id, title, description, completed, due_date, created_at, updated_at"
+	f.where = append(f.where, "search_vector @@ "+match)
 
-	err = conn.QueryRow(ctx, prepareQuery, req.Title, req.Description, req.Completed, req.DueDate, req.Id, uuidUserId).Scan(&todo.Id, &todo.Title, &todo.Description, &todo.Completed, &todo.DueDate, &todo.CreatedAt, &todo.UpdatedAt)
+	order := []string{"rank DESC", "id"}
+
+	if len(query.Sort) > 0 {
+		order = todosOrder(query.Sort, false)
+	}
+
+	prepareQuery := fmt.Sprintf(`SELECT %s, ts_rank_cd(search_vector, %s) AS rank,
+			ts_headline('simple', %s, %s, '%s, HighlightAll=true'),
+			ts_headline('simple', %s, %s, '%s, MaxWords=20, MinWords=5, MaxFragments=2')
+		FROM todos WHERE %s ORDER BY %s`,
+		todoColumns, match, escapeHTML("title"), match, searchHighlight, escapeHTML("description"), match, searchHighlight, strings.Join(f.where, " AND "), strings.Join(order, ", "))
+
+	return prepareQuery + fmt.Sprintf(" LIMIT %s OFFSET %s", f.arg(query.Limit), f.arg(query.Offset)), f.args
+}
+
+// matched words are wrapped in mark tags, the text is html escaped first
+const searchHighlight = "StartSel=<mark>, StopSel=</mark>"
+
+// escapeHTML returns the sql escaping the html of a text column, the escaped
+// characters are not part of words so the search still matches the same words
+func escapeHTML(column string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&#34;"}, {"''", "&#39;"}} {
+		column = fmt.Sprintf("replace(%s, '%s', '%s')", column, r[0], r[1])
+	}
+
+	return column
+}
+
+// todosFilter collects the conditions of a todo list query and their arguments
+type todosFilter struct {
+	where []string
+	args  []any
+}
+
+// newTodosFilter scopes the query to the workspace and adds the filters
+// shared by the list and the search
+func newTodosFilter(workspaceId uuid.UUID, query types.TodosQuery) *todosFilter {
+	f := &todosFilter{where: []string{"workspace_id = $1"}, args: []any{workspaceId}}
+
+	if query.Completed != nil {
+		f.add("completed = ?", *query.Completed)
+	}
+
+	if query.DueBefore != nil {
+		f.add("due_date < ?", query.DueBefore.UTC())
+	}
+
+	if query.DueAfter != nil {
+		f.add("due_date >= ?", query.DueAfter.UTC())
+	}
+
+	if query.CreatedAfter != nil {
+		f.add("created_at >= ?", query.CreatedAfter.UTC())
+	}
+
+	if query.Overdue {
+		f.where = append(f.where, "NOT completed", "due_date < CURRENT_TIMESTAMP")
+	}
+
+	if query.ProjectId != nil {
+		f.add("project_id = ?", *query.ProjectId)
+	}
+
+	if query.Inbox {
+		f.where = append(f.where, "project_id IS NULL")
+	}
+
+	if len(query.Tags) > 0 {
+		names := make([]string, len(query.Tags))
+
+		for i, name := range query.Tags {
+			names[i] = strings.ToLower(name)
+		}
+
+		// a repeated name would never be counted twice
+		slices.Sort(names)
+		names = slices.Compact(names)
+
+		tagged := "SELECT %s FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.todo_id = todos.id AND lower(t.name) = ANY(?)"
+
+		// names are unique regardless of case, every name matches a single tag
+		if query.TagsMatchAll {
+			f.add(fmt.Sprintf("(%s) = %d", fmt.Sprintf(tagged, "count(*)"), len(names)), names)
+		} else {
+			f.add(fmt.Sprintf("EXISTS (%s)", fmt.Sprintf(tagged, "1")), names)
+		}
+	}
+
+	return f
+}
+
+// arg binds value and returns its placeholder
+func (f *todosFilter) arg(value any) string {
+	f.args = append(f.args, value)
+
+	return fmt.Sprintf("$%d", len(f.args))
+}
+
+// add adds a condition, ? is replaced by the placeholder of value
+func (f *todosFilter) add(condition string, value any) {
+	f.where = append(f.where, strings.ReplaceAll(condition, "?", f.arg(value)))
+}
+
+// todosOrder returns the order by clauses of sort, the id breaks ties.
+// reverse flips every column, nulls then sort first.
+func todosOrder(sort []types.TodosSort, reverse bool) []string {
+	order := make([]string, 0, len(sort)+1)
+
+	for _, field := range sort {
+		// the whitelist is checked again, a column is never taken from the request as is
+		if !slices.Contains(types.TodosSortColumns, field.Column) {
+			continue
+		}
+
+		switch {
+		case field.Desc && !reverse:
+			order = append(order, field.Column+" DESC NULLS LAST")
+		case field.Desc:
+			order = append(order, field.Column+" ASC NULLS FIRST")
+		case !reverse:
+			order = append(order, field.Column+" ASC NULLS LAST")
+		default:
+			order = append(order, field.Column+" DESC NULLS FIRST")
+		}
+	}
+
+	if reverse {
+		return append(order, "id DESC")
+	}
+
+	return append(order, "id")
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// GetById returns a todo of the workspace, it is always read from the database
+func (s *TodosStore) GetById(ctx context.Context, id uuid.UUID) (*types.Todos, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
 
 	if err != nil {
 		return nil, err
 	}
+	// defer release connection
+	defer conn.Release()
 
-	err = deleteCache(ctx, "todos", s.redis)
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleViewer)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &todo, nil
+	return getTodo(ctx, conn, id, workspaceId)
+}
+
+// Update replaces the columns of a todo, its tags, project and auto complete
+// are kept when missing from the request
+func (s *TodosStore) Update(ctx context.Context, id uuid.UUID, req types.TodosPutRequestBody) (*types.Todos, error) {
+	set := "title = $1, description = $2, completed = $3, due_date = $4, auto_complete = COALESCE($5, auto_complete)"
+	args := []any{req.Title, req.Description, req.Completed, req.DueDate, req.AutoComplete}
+
+	// null moves the todo to the inbox
+	if req.ProjectId.Set {
+		set += ", project_id = $6"
+		args = append(args, req.ProjectId.Id)
+	}
+
+	return s.update(ctx, id, req.Tags, set, args...)
+}
+
+// Patch applies the fields of a merge patch, the columns come from the patch
+// tags of types.Todos and never from the request
+func (s *TodosStore) Patch(ctx context.Context, id uuid.UUID, patch []libs.PatchField) (*types.Todos, error) {
+	// an empty patch changes nothing
+	if len(patch) == 0 {
+		return s.GetById(ctx, id)
+	}
+
+	var set []string
+	var args []any
+	var tags []string
+
+	for _, field := range patch {
+		// tags are not a column of the todo
+		if field.Column == "tags" {
+			tags, _ = field.Value.([]string)
+
+			// null clears the tags
+			if tags == nil {
+				tags = []string{}
+			}
+
+			continue
+		}
+
+		args = append(args, field.Value)
+		set = append(set, fmt.Sprintf("%s = $%d", field.Column, len(args)))
+	}
+
+	// changing the tags alone still touches the todo
+	if len(set) == 0 {
+		set = append(set, "updated_at = CURRENT_TIMESTAMP")
+	}
+
+	return s.update(ctx, id, tags, strings.Join(set, ", "), args...)
 }
 
-func (s *TodosStore) Delete(ctx context.Context, req types.TodosDeleteRequestBody) error {
+// update applies the set clause to a todo of the workspace, its placeholders
+// are numbered from $1 and bound to args. Tags replace the tags of the todo
+// unless they are nil.
+func (s *TodosStore) update(ctx context.Context, id uuid.UUID, tags []string, set string, args ...any) (*types.Todos, error) {
 	// acquire connection
 	conn, err := s.db.Acquire(ctx)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// defer release connection
 	defer conn.Release()
 
-	// get user id from context
-	userId := ctx.Value(types.UserIdKey("user-id"))
-	uuidUserId, err := uuid.Parse(userId.(string))
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
 	}
 
+	defer tx.Rollback(ctx)
+
 	// perform query
-	prepareQuery := "DELETE FROM todos WHERE id = $1 AND user_id = $2"
+	prepareQuery := fmt.Sprintf("UPDATE todos SET %s WHERE id = $%d AND workspace_id = $%d", set, len(args)+1, len(args)+2)
 
-	_, err = conn.Exec(ctx, prepareQuery, req.Id, uuidUserId)
+	tag, err := tx.Exec(ctx, prepareQuery, append(args, id, workspaceId)...)
+
+	if isProjectViolation(err) {
+		return nil, types.ErrInvalidProject
+	}
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, types.ErrTodoNotFound
+	}
+
+	if tags != nil {
+		err = setTodoTags(ctx, tx, workspaceId, id, tags)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the checklist wins over the completion given with auto_complete
+	err = syncChecklistCompletion(ctx, tx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := getTodo(ctx, tx, id, workspaceId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
 	}
 
-	return nil
+	err = deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
 }
 
-func deleteCache(ctx context.Context, key string, r *redis.Client) error {
-	_, err := r.Get(ctx, key).Result()
+func (s *TodosStore) Delete(ctx context.Context, id uuid.UUID) error {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
 
-	if err == redis.Nil {
-		return nil
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	workspaceId, err := requestWorkspace(ctx, conn, types.WorkspaceRoleMember)
+
+	if err != nil {
+		return err
 	}
 
-	err = r.Del(ctx, key).Err()
+	// perform query
+	prepareQuery := "DELETE FROM todos WHERE id = $1 AND workspace_id = $2"
+
+	tag, err := conn.Exec(ctx, prepareQuery, id, workspaceId)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if tag.RowsAffected() == 0 {
+		return types.ErrTodoNotFound
+	}
+
+	return deleteCache(ctx, todosCacheKey(workspaceId), s.redis)
+}
+
+// todosCacheKey is the cache key of the todos of a workspace
+func todosCacheKey(workspaceId uuid.UUID) string {
+	return "todos:" + workspaceId.String()
+}
+
+func deleteCache(ctx context.Context, key string, r *redis.Client) error {
+	return r.Del(ctx, key).Err()
 }
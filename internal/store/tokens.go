@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/odev-swe/todoapp/libs"
+)
+
+type TokensStore struct {
+	db *pgxpool.Pool
+}
+
+func NewTokensStore(db *pgxpool.Pool) *TokensStore {
+	return &TokensStore{
+		db: db,
+	}
+}
+
+const tokenColumns = "id, name, token_prefix, scopes, expires_at, last_used_at, created_at"
+
+func scanToken(row pgx.Row, token *types.PersonalAccessToken) error {
+	return row.Scan(&token.Id, &token.Name, &token.Prefix, &token.Scopes, &token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+}
+
+func (s *TokensStore) Create(ctx context.Context, req types.PersonalAccessTokenPostRequestBody) (*types.PersonalAccessToken, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := libs.RandomToken(32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plain := types.PatPrefix + secret
+
+	scopes := req.Scopes
+
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	// perform query
+	var token types.PersonalAccessToken
+
+	prepareQuery := "INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING " + tokenColumns
+
+	err = scanToken(conn.QueryRow(ctx, prepareQuery, uuidUserId, req.Name, libs.HashToken(plain), plain[:len(types.PatPrefix)+4], scopes, req.ExpiresAt), &token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	token.Token = plain
+
+	return &token, nil
+}
+
+func (s *TokensStore) Get(ctx context.Context) ([]types.PersonalAccessToken, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	prepareQuery := "SELECT " + tokenColumns + " FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at DESC"
+
+	rows, err := conn.Query(ctx, prepareQuery, uuidUserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tokens := []types.PersonalAccessToken{}
+
+	for rows.Next() {
+		var token types.PersonalAccessToken
+
+		err = scanToken(rows, &token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (s *TokensStore) GetById(ctx context.Context, id uuid.UUID) (*types.PersonalAccessToken, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var token types.PersonalAccessToken
+
+	prepareQuery := "SELECT " + tokenColumns + " FROM personal_access_tokens WHERE id = $1 AND user_id = $2"
+
+	err = scanToken(conn.QueryRow(ctx, prepareQuery, id, uuidUserId), &token)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTokenNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (s *TokensStore) Update(ctx context.Context, id uuid.UUID, req types.PersonalAccessTokenPatchRequestBody) (*types.PersonalAccessToken, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var token types.PersonalAccessToken
+
+	// missing fields keep their value
+	prepareQuery := "UPDATE personal_access_tokens SET name = COALESCE($1, name), scopes = COALESCE($2, scopes) WHERE id = $3 AND user_id = $4 RETURNING " + tokenColumns
+
+	err = scanToken(conn.QueryRow(ctx, prepareQuery, req.Name, req.Scopes, id, uuidUserId), &token)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrTokenNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (s *TokensStore) Delete(ctx context.Context, id uuid.UUID) error {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	tag, err := conn.Exec(ctx, "DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2", id, uuidUserId)
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return types.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// Authenticate resolves a plain personal access token and records the use.
+// It also returns the owner's id, verification and role, tokens of disabled
+// users are rejected.
+func (s *TokensStore) Authenticate(ctx context.Context, plain string) (*types.PersonalAccessToken, *types.User, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	var token types.PersonalAccessToken
+	var user types.User
+
+	prepareQuery := `UPDATE personal_access_tokens p SET last_used_at = CURRENT_TIMESTAMP FROM users u
+		WHERE p.token_hash = $1 AND u.id = p.user_id AND u.disabled_at IS NULL AND (p.expires_at IS NULL OR p.expires_at > CURRENT_TIMESTAMP)
+		RETURNING p.id, p.user_id, p.scopes, u.verified_at, u.role`
+
+	err = conn.QueryRow(ctx, prepareQuery, libs.HashToken(plain)).Scan(&token.Id, &token.UserId, &token.Scopes, &user.VerifiedAt, &user.Role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, types.ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user.Id = token.UserId
+
+	return &token, &user, nil
+}
+
+// deleteUserTokens deletes the personal access tokens of a user created before
+// the given time, they would otherwise keep working after the credentials
+// that created them are gone
+func deleteUserTokens(ctx context.Context, db executor, userId uuid.UUID, before time.Time) error {
+	_, err := db.Exec(ctx, "DELETE FROM personal_access_tokens WHERE user_id = $1 AND created_at < $2", userId, before)
+
+	return err
+}
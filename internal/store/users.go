@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type UsersStore struct {
+	db *pgxpool.Pool
+}
+
+func NewUsersStore(db *pgxpool.Pool) *UsersStore {
+	return &UsersStore{
+		db: db,
+	}
+}
+
+const userColumns = "id, email, verified_at, role, disabled_at, display_name, timezone, locale, created_at, updated_at"
+
+func scanUser(row pgx.Row, user *types.User) error {
+	return row.Scan(&user.Id, &user.Email, &user.VerifiedAt, &user.Role, &user.DisabledAt, &user.DisplayName, &user.Timezone, &user.Locale, &user.CreatedAt, &user.UpdatedAt)
+}
+
+func (s *UsersStore) Get(ctx context.Context, query types.UsersQuery) ([]types.User, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	prepareQuery := "SELECT " + userColumns + " FROM users ORDER BY created_at, id LIMIT $1 OFFSET $2"
+
+	rows, err := conn.Query(ctx, prepareQuery, query.Limit, query.Offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := []types.User{}
+
+	for rows.Next() {
+		var user types.User
+
+		err = scanUser(rows, &user)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// PromoteAdmins grants the admin role to the users with the given emails
+func (s *UsersStore) PromoteAdmins(ctx context.Context, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	prepareQuery := "UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE lower(email) = ANY($2) AND role <> $1"
+
+	lowered := make([]string, len(emails))
+
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	_, err = conn.Exec(ctx, prepareQuery, types.RoleAdmin, lowered)
+
+	return err
+}
@@ -0,0 +1,469 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/odev-swe/todoapp/internal/types"
+)
+
+type WorkspacesStore struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkspacesStore(db *pgxpool.Pool) *WorkspacesStore {
+	return &WorkspacesStore{
+		db: db,
+	}
+}
+
+const personalWorkspaceName = "Personal"
+
+const memberColumns = "u.id, u.email, u.display_name, m.role, m.created_at"
+
+func scanMember(row pgx.Row, member *types.WorkspaceMember) error {
+	return row.Scan(&member.UserId, &member.Email, &member.DisplayName, &member.Role, &member.CreatedAt)
+}
+
+func (s *WorkspacesStore) Get(ctx context.Context) ([]types.Workspace, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	prepareQuery := `SELECT w.id, w.name, w.personal_user_id IS NOT NULL, m.role, w.created_at, w.updated_at
+		FROM workspace_members m JOIN workspaces w ON w.id = m.workspace_id
+		WHERE m.user_id = $1 ORDER BY w.personal_user_id IS NULL, w.name, w.id`
+
+	rows, err := conn.Query(ctx, prepareQuery, uuidUserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	workspaces := []types.Workspace{}
+
+	for rows.Next() {
+		var workspace types.Workspace
+
+		err = rows.Scan(&workspace.Id, &workspace.Name, &workspace.Personal, &workspace.Role, &workspace.CreatedAt, &workspace.UpdatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// Create adds a shared workspace owned by the current user
+func (s *WorkspacesStore) Create(ctx context.Context, req types.WorkspacePostRequestBody) (*types.Workspace, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	workspace := types.Workspace{Role: types.WorkspaceRoleOwner}
+
+	prepareQuery := "INSERT INTO workspaces (name) VALUES ($1) RETURNING id, name, created_at, updated_at"
+
+	err = tx.QueryRow(ctx, prepareQuery, req.Name).Scan(&workspace.Id, &workspace.Name, &workspace.CreatedAt, &workspace.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)", workspace.Id, uuidUserId, types.WorkspaceRoleOwner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+// Delete removes a shared workspace and its todos, only owners can do it
+func (s *WorkspacesStore) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.withMembership(ctx, id, func(tx pgx.Tx, userId uuid.UUID, role string, personal bool) error {
+		if role != types.WorkspaceRoleOwner {
+			return types.ErrWorkspaceForbidden
+		}
+
+		if personal {
+			return types.ErrPersonalWorkspace
+		}
+
+		_, err := tx.Exec(ctx, "DELETE FROM workspaces WHERE id = $1", id)
+
+		return err
+	})
+}
+
+// GetMembers lists the members of a workspace the current user belongs to
+func (s *WorkspacesStore) GetMembers(ctx context.Context, id uuid.UUID) ([]types.WorkspaceMember, error) {
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return nil, err
+	}
+
+	prepareQuery := `SELECT ` + memberColumns + ` FROM workspace_members m JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1 AND EXISTS (SELECT 1 FROM workspace_members WHERE workspace_id = $1 AND user_id = $2)
+		ORDER BY m.created_at, u.id`
+
+	rows, err := conn.Query(ctx, prepareQuery, id, uuidUserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	members := []types.WorkspaceMember{}
+
+	for rows.Next() {
+		var member types.WorkspaceMember
+
+		err = scanMember(rows, &member)
+
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// a workspace always has an owner, no rows means the user is not a member
+	if len(members) == 0 {
+		return nil, types.ErrWorkspaceNotFound
+	}
+
+	return members, nil
+}
+
+// AddMember adds the user owning the email to a shared workspace. Admins can
+// add anyone but owners, who can only be added by another owner.
+func (s *WorkspacesStore) AddMember(ctx context.Context, id uuid.UUID, req types.WorkspaceMemberPostRequestBody) (*types.WorkspaceMember, error) {
+	var member types.WorkspaceMember
+
+	err := s.withMembership(ctx, id, func(tx pgx.Tx, userId uuid.UUID, role string, personal bool) error {
+		if !canGrant(role, req.Role) {
+			return types.ErrWorkspaceForbidden
+		}
+
+		if personal {
+			return types.ErrPersonalWorkspace
+		}
+
+		prepareQuery := `WITH inserted AS (
+				INSERT INTO workspace_members (workspace_id, user_id, role) SELECT $1, id, $3 FROM users WHERE email = $2
+				ON CONFLICT DO NOTHING RETURNING user_id, role, created_at
+			)
+			SELECT u.id, u.email, u.display_name, m.role, m.created_at FROM inserted m JOIN users u ON u.id = m.user_id`
+
+		err := scanMember(tx.QueryRow(ctx, prepareQuery, id, req.Email, req.Role), &member)
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		// nothing inserted, either the email is unknown or already a member
+		var exists bool
+
+		err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)", req.Email).Scan(&exists)
+
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return types.ErrMemberExists
+		}
+
+		return types.ErrUserNotFound
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// UpdateMember changes the role of a member, owners are the only ones who can
+// grant or take away the owner role and the last owner can not be demoted
+func (s *WorkspacesStore) UpdateMember(ctx context.Context, id uuid.UUID, memberId uuid.UUID, req types.WorkspaceMemberPatchRequestBody) (*types.WorkspaceMember, error) {
+	var member types.WorkspaceMember
+
+	err := s.withMembership(ctx, id, func(tx pgx.Tx, userId uuid.UUID, role string, personal bool) error {
+		current, err := memberRole(ctx, tx, id, memberId)
+
+		if err != nil {
+			return err
+		}
+
+		if !canGrant(role, current) || !canGrant(role, req.Role) {
+			return types.ErrWorkspaceForbidden
+		}
+
+		if current == types.WorkspaceRoleOwner && req.Role != types.WorkspaceRoleOwner {
+			if err = ensureOtherOwner(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+
+		prepareQuery := `WITH updated AS (
+				UPDATE workspace_members SET role = $3 WHERE workspace_id = $1 AND user_id = $2 RETURNING user_id, role, created_at
+			)
+			SELECT u.id, u.email, u.display_name, m.role, m.created_at FROM updated m JOIN users u ON u.id = m.user_id`
+
+		return scanMember(tx.QueryRow(ctx, prepareQuery, id, memberId, req.Role), &member)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// RemoveMember takes a member out of a workspace. Anyone can leave, removing
+// someone else follows the same rules as changing their role.
+func (s *WorkspacesStore) RemoveMember(ctx context.Context, id uuid.UUID, memberId uuid.UUID) error {
+	return s.withMembership(ctx, id, func(tx pgx.Tx, userId uuid.UUID, role string, personal bool) error {
+		current, err := memberRole(ctx, tx, id, memberId)
+
+		if err != nil {
+			return err
+		}
+
+		if memberId != userId && !canGrant(role, current) {
+			return types.ErrWorkspaceForbidden
+		}
+
+		if current == types.WorkspaceRoleOwner {
+			if err = ensureOtherOwner(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2", id, memberId)
+
+		return err
+	})
+}
+
+// withMembership runs fn in a transaction holding a lock on the workspace,
+// with the role of the current user in it. Workspaces the user is not a
+// member of are reported as not found.
+func (s *WorkspacesStore) withMembership(ctx context.Context, id uuid.UUID, fn func(tx pgx.Tx, userId uuid.UUID, role string, personal bool) error) error {
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return err
+	}
+
+	// acquire connection
+	conn, err := s.db.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+	// defer release connection
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var role string
+	var personal bool
+
+	prepareQuery := `SELECT m.role, w.personal_user_id IS NOT NULL FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+		WHERE w.id = $1 FOR UPDATE OF w`
+
+	err = tx.QueryRow(ctx, prepareQuery, id, uuidUserId).Scan(&role, &personal)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return types.ErrWorkspaceNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx, uuidUserId, role, personal)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// memberRole returns the role of a user in the workspace
+func memberRole(ctx context.Context, db executor, workspaceId uuid.UUID, userId uuid.UUID) (string, error) {
+	var role string
+
+	err := db.QueryRow(ctx, "SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2", workspaceId, userId).Scan(&role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", types.ErrMemberNotFound
+	}
+
+	return role, err
+}
+
+// ensureOtherOwner fails when the workspace has a single owner left
+func ensureOtherOwner(ctx context.Context, db executor, workspaceId uuid.UUID) error {
+	var owners int
+
+	err := db.QueryRow(ctx, "SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND role = $2", workspaceId, types.WorkspaceRoleOwner).Scan(&owners)
+
+	if err != nil {
+		return err
+	}
+
+	if owners < 2 {
+		return types.ErrLastOwner
+	}
+
+	return nil
+}
+
+// canGrant reports whether a member with the given role can hand out or take
+// away target, admins manage everyone below owner
+func canGrant(role string, target string) bool {
+	if role == types.WorkspaceRoleOwner {
+		return true
+	}
+
+	return role == types.WorkspaceRoleAdmin && target != types.WorkspaceRoleOwner
+}
+
+// createPersonalWorkspace gives a new user the workspace used when a request
+// selects none
+func createPersonalWorkspace(ctx context.Context, db executor, userId uuid.UUID) error {
+	var id uuid.UUID
+
+	err := db.QueryRow(ctx, "INSERT INTO workspaces (name, personal_user_id) VALUES ($1, $2) RETURNING id", personalWorkspaceName, userId).Scan(&id)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, "INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)", id, userId, types.WorkspaceRoleOwner)
+
+	return err
+}
+
+// requestWorkspace resolves the workspace of the request for the current user
+func requestWorkspace(ctx context.Context, db executor, minRole string) (uuid.UUID, error) {
+	// get user id from context
+	userId := ctx.Value(types.UserIdKey("user-id"))
+	uuidUserId, err := uuid.Parse(userId.(string))
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return resolveWorkspace(ctx, db, uuidUserId, minRole)
+}
+
+// resolveWorkspace returns the workspace selected in the context, or the
+// personal workspace of the user, as long as the user holds at least the
+// min role in it. Workspaces the user is not a member of are not found.
+func resolveWorkspace(ctx context.Context, db executor, userId uuid.UUID, min string) (uuid.UUID, error) {
+	var selected *uuid.UUID
+
+	if id, ok := ctx.Value(types.WorkspaceIdKey("workspace-id")).(uuid.UUID); ok {
+		selected = &id
+	}
+
+	var id uuid.UUID
+	var role string
+
+	prepareQuery := `SELECT w.id, m.role FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id AND m.user_id = $1
+		WHERE w.id = $2 OR ($2::uuid IS NULL AND w.personal_user_id = $1)`
+
+	err := db.QueryRow(ctx, prepareQuery, userId, selected).Scan(&id, &role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, types.ErrWorkspaceNotFound
+	}
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if !types.WorkspaceRoleAtLeast(role, min) {
+		return uuid.Nil, types.ErrWorkspaceForbidden
+	}
+
+	return id, nil
+}
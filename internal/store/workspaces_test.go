@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveWorkspace(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	workspaces := NewWorkspacesStore(db)
+
+	owner, ownerCtx := testUser(t, s)
+	viewer, viewerCtx := testUser(t, s)
+	outsider, _ := testUser(t, s)
+
+	team, err := workspaces.Create(ownerCtx, types.WorkspacePostRequestBody{Name: "Team"})
+	require.NoError(t, err)
+
+	_, err = workspaces.AddMember(ownerCtx, team.Id, types.WorkspaceMemberPostRequestBody{Email: viewer.Email, Role: types.WorkspaceRoleViewer})
+	require.NoError(t, err)
+
+	selected := context.WithValue(context.Background(), types.WorkspaceIdKey("workspace-id"), team.Id)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		userId      uuid.UUID
+		min         string
+		expectedErr error
+	}{
+		{"Owner Writes", selected, owner.Id, types.WorkspaceRoleMember, nil},
+		{"Viewer Reads", selected, viewer.Id, types.WorkspaceRoleViewer, nil},
+		{"Viewer Writes", selected, viewer.Id, types.WorkspaceRoleMember, types.ErrWorkspaceForbidden},
+		{"Viewer Manages Members", selected, viewer.Id, types.WorkspaceRoleAdmin, types.ErrWorkspaceForbidden},
+		{"Non Member Reads", selected, outsider.Id, types.WorkspaceRoleViewer, types.ErrWorkspaceNotFound},
+		{"Personal Workspace", context.Background(), outsider.Id, types.WorkspaceRoleOwner, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := resolveWorkspace(tt.ctx, db, tt.userId, tt.min)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEqual(t, uuid.Nil, id)
+		})
+	}
+
+	// without the header the viewer works in their own personal workspace
+	personal, err := resolveWorkspace(viewerCtx, db, viewer.Id, types.WorkspaceRoleOwner)
+	require.NoError(t, err)
+	assert.NotEqual(t, team.Id, personal)
+}
+
+func TestCrossWorkspaceAccess(t *testing.T) {
+	db := testDB(t)
+	s := testAuthStore(db)
+	workspaces := NewWorkspacesStore(db)
+
+	// the denied calls return before the cache is touched
+	todos := NewTodosStore(db, nil)
+	tags := NewTagsStore(db, nil)
+	projects := NewProjectsStore(db, nil)
+
+	owner, ownerCtx := testUser(t, s)
+	viewer, viewerCtx := testUser(t, s)
+	_, outsiderCtx := testUser(t, s)
+
+	team, err := workspaces.Create(ownerCtx, types.WorkspacePostRequestBody{Name: "Team"})
+	require.NoError(t, err)
+
+	_, err = workspaces.AddMember(ownerCtx, team.Id, types.WorkspaceMemberPostRequestBody{Email: viewer.Email, Role: types.WorkspaceRoleViewer})
+	require.NoError(t, err)
+
+	ownerCtx = context.WithValue(ownerCtx, types.WorkspaceIdKey("workspace-id"), team.Id)
+
+	var todoId uuid.UUID
+
+	err = db.QueryRow(ownerCtx, "INSERT INTO todos (title, user_id, workspace_id) VALUES ($1, $2, $3) RETURNING id", "Plan", owner.Id, team.Id).Scan(&todoId)
+	require.NoError(t, err)
+
+	tag, err := tags.Create(ownerCtx, types.TagPostRequestBody{Name: "work", Color: types.DefaultTagColor})
+	require.NoError(t, err)
+
+	project, err := projects.Create(ownerCtx, types.ProjectPostRequestBody{Name: "Launch", Color: types.DefaultTagColor})
+	require.NoError(t, err)
+
+	t.Run("Viewer", func(t *testing.T) {
+		ctx := context.WithValue(viewerCtx, types.WorkspaceIdKey("workspace-id"), team.Id)
+
+		_, err := todos.GetById(ctx, todoId)
+		assert.NoError(t, err)
+
+		_, err = tags.GetById(ctx, tag.Id)
+		assert.NoError(t, err)
+
+		_, err = projects.GetById(ctx, project.Id)
+		assert.NoError(t, err)
+
+		_, err = todos.Create(ctx, types.TodosPostRequestBody{Title: "Sneak in"})
+		assert.ErrorIs(t, err, types.ErrWorkspaceForbidden)
+
+		assert.ErrorIs(t, todos.Delete(ctx, todoId), types.ErrWorkspaceForbidden)
+
+		_, err = tags.Create(ctx, types.TagPostRequestBody{Name: "home", Color: types.DefaultTagColor})
+		assert.ErrorIs(t, err, types.ErrWorkspaceForbidden)
+
+		assert.ErrorIs(t, tags.Delete(ctx, tag.Id), types.ErrWorkspaceForbidden)
+
+		_, err = projects.Create(ctx, types.ProjectPostRequestBody{Name: "Side", Color: types.DefaultTagColor})
+		assert.ErrorIs(t, err, types.ErrWorkspaceForbidden)
+
+		assert.ErrorIs(t, projects.Delete(ctx, project.Id, ""), types.ErrWorkspaceForbidden)
+	})
+
+	t.Run("Non Member", func(t *testing.T) {
+		ctx := context.WithValue(outsiderCtx, types.WorkspaceIdKey("workspace-id"), team.Id)
+
+		_, err := todos.GetById(ctx, todoId)
+		assert.ErrorIs(t, err, types.ErrWorkspaceNotFound)
+
+		_, err = tags.Get(ctx)
+		assert.ErrorIs(t, err, types.ErrWorkspaceNotFound)
+
+		_, err = projects.GetById(ctx, project.Id)
+		assert.ErrorIs(t, err, types.ErrWorkspaceNotFound)
+
+		assert.ErrorIs(t, todos.Delete(ctx, todoId), types.ErrWorkspaceNotFound)
+	})
+
+	t.Run("Own Workspace", func(t *testing.T) {
+		// the ids of another workspace are not found from the personal one
+		_, err := todos.GetById(outsiderCtx, todoId)
+		assert.ErrorIs(t, err, types.ErrTodoNotFound)
+
+		_, err = tags.GetById(outsiderCtx, tag.Id)
+		assert.ErrorIs(t, err, types.ErrTagNotFound)
+
+		_, err = projects.GetById(outsiderCtx, project.Id)
+		assert.ErrorIs(t, err, types.ErrProjectNotFound)
+	})
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"time"
+	// timezones are validated without relying on the zoneinfo of the host
+	_ "time/tzdata"
+
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidPassword    = errors.New("password is incorrect")
+	ErrInvalidDisplayName = errors.New("display name is too long")
+	ErrInvalidTimezone    = errors.New("invalid timezone")
+	ErrInvalidLocale      = errors.New("invalid locale")
+	ErrReauthRequired     = errors.New("log in again to confirm this change")
+)
+
+// ReauthWindow is how recently an account without a password must have logged
+// in to confirm a sensitive change
+const ReauthWindow = 10 * time.Minute
+
+// ProfilePatchRequestBody only changes the fields that are set
+type ProfilePatchRequestBody struct {
+	DisplayName *string `json:"display_name,omitempty" example:"Admin"`
+	// IANA timezone name
+	Timezone *string `json:"timezone,omitempty" example:"Europe/Amsterdam"`
+	// BCP 47 language tag
+	Locale *string `json:"locale,omitempty" example:"en-US"`
+}
+
+// Validate checks the fields that are set and normalizes them in place
+func (b *ProfilePatchRequestBody) Validate() error {
+	if b.DisplayName != nil {
+		name := strings.TrimSpace(*b.DisplayName)
+
+		if len(name) > 255 {
+			return ErrInvalidDisplayName
+		}
+
+		b.DisplayName = &name
+	}
+
+	// the local zone depends on the host and is never a valid choice
+	if b.Timezone != nil {
+		if _, err := time.LoadLocation(*b.Timezone); err != nil || *b.Timezone == "" || *b.Timezone == "Local" {
+			return ErrInvalidTimezone
+		}
+	}
+
+	if b.Locale != nil {
+		tag, err := language.Parse(*b.Locale)
+
+		if err != nil {
+			return ErrInvalidLocale
+		}
+
+		locale := tag.String()
+		b.Locale = &locale
+	}
+
+	return nil
+}
+
+type ChangePasswordRequestBody struct {
+	// left empty by accounts without a password
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type DeleteAccountRequestBody struct {
+	// left empty by accounts without a password
+	Password string `json:"password"`
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestProfilePatchValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     ProfilePatchRequestBody
+		expected error
+		locale   string
+	}{
+		{"Empty Patch", ProfilePatchRequestBody{}, nil, ""},
+		{"Valid Timezone", ProfilePatchRequestBody{Timezone: ptr("Europe/Amsterdam")}, nil, ""},
+		{"Unknown Timezone", ProfilePatchRequestBody{Timezone: ptr("Mars/Olympus")}, ErrInvalidTimezone, ""},
+		{"Local Timezone", ProfilePatchRequestBody{Timezone: ptr("Local")}, ErrInvalidTimezone, ""},
+		{"Canonical Locale", ProfilePatchRequestBody{Locale: ptr("en-us")}, nil, "en-US"},
+		{"Invalid Locale", ProfilePatchRequestBody{Locale: ptr("not a locale")}, ErrInvalidLocale, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.body.Validate()
+
+			assert.Equal(t, tt.expected, err)
+
+			if tt.locale != "" {
+				assert.Equal(t, tt.locale, *tt.body.Locale)
+			}
+		})
+	}
+}
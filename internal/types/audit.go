@@ -0,0 +1,100 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// security events recorded in the audit log
+const (
+	AuditRegistered               = "account.registered"
+	AuditAccountDeleted           = "account.deleted"
+	AuditEmailVerified            = "email.verified"
+	AuditLoginSucceeded           = "login.succeeded"
+	AuditLoginFailed              = "login.failed"
+	AuditLoginMfaRequired         = "login.mfa_required"
+	AuditLogout                   = "logout"
+	AuditLogoutAll                = "logout.all"
+	AuditSessionRevoked           = "session.revoked"
+	AuditRefreshTokenReused       = "refresh_token.reused"
+	AuditPasswordChanged          = "password.changed"
+	AuditPasswordResetRequested   = "password.reset_requested"
+	AuditPasswordReset            = "password.reset"
+	AuditMagicLinkRequested       = "magic_link.requested"
+	AuditMfaEnabled               = "mfa.enabled"
+	AuditMfaDisabled              = "mfa.disabled"
+	AuditRecoveryCodesRegenerated = "mfa.recovery_codes_regenerated"
+	AuditTokenCreated             = "personal_access_token.created"
+	AuditTokenRevoked             = "personal_access_token.revoked"
+	AuditUserDisabled             = "user.disabled"
+	AuditUserEnabled              = "user.enabled"
+	AuditPasswordResetForced      = "user.password_reset_forced"
+	AuditRoleChanged              = "user.role_changed"
+	AuditLoginUnlocked            = "user.login_unlocked"
+)
+
+// AuditGenesisHash is the previous hash of the first event
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+type AuditEvent struct {
+	Id   int64  `json:"id"`
+	Type string `json:"type"`
+	// the authenticated user, missing for anonymous requests such as failed logins
+	ActorId   *uuid.UUID        `json:"actor_id,omitempty"`
+	Ip        string            `json:"ip"`
+	UserAgent string            `json:"user_agent"`
+	Metadata  map[string]string `json:"metadata"`
+	CreatedAt time.Time         `json:"created_at"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+// ComputeHash returns the sha256 of the previous hash and every field of the
+// event but its id, so changing, removing or reordering events breaks the chain
+func (e *AuditEvent) ComputeHash() string {
+	actor := ""
+
+	if e.ActorId != nil {
+		actor = e.ActorId.String()
+	}
+
+	metadata := e.Metadata
+
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	// map keys are sorted, the encoding is stable
+	encoded, _ := json.Marshal(metadata)
+
+	h := sha256.New()
+
+	for _, field := range []string{e.PrevHash, e.Type, actor, e.Ip, e.UserAgent, string(encoded), e.CreatedAt.UTC().Format(time.RFC3339Nano)} {
+		// length prefixed so a field can not spill into the next one
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type AuditQuery struct {
+	Type    string
+	ActorId *uuid.UUID
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+type AuditVerification struct {
+	Valid   bool `json:"valid"`
+	Checked int  `json:"checked"`
+	// id of the first event whose hash does not match
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEventComputeHash(t *testing.T) {
+	actor := uuid.New()
+	other := uuid.New()
+
+	newEvent := func() AuditEvent {
+		return AuditEvent{
+			Type:      AuditLoginSucceeded,
+			ActorId:   &actor,
+			Ip:        "203.0.113.7",
+			UserAgent: "curl/8.0",
+			Metadata:  map[string]string{"method": "password"},
+			CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 123456000, time.UTC),
+			PrevHash:  AuditGenesisHash,
+		}
+	}
+
+	base := newEvent()
+	hash := base.ComputeHash()
+
+	assert.Len(t, hash, 64)
+
+	tests := []struct {
+		name   string
+		tamper func(e *AuditEvent)
+	}{
+		{"Previous Hash", func(e *AuditEvent) { e.PrevHash = hash }},
+		{"Type", func(e *AuditEvent) { e.Type = AuditLoginFailed }},
+		{"Actor", func(e *AuditEvent) { e.ActorId = &other }},
+		{"Anonymous Actor", func(e *AuditEvent) { e.ActorId = nil }},
+		{"Ip", func(e *AuditEvent) { e.Ip = "203.0.113.8" }},
+		{"User Agent", func(e *AuditEvent) { e.UserAgent = "curl/8.1" }},
+		{"Metadata", func(e *AuditEvent) { e.Metadata["method"] = "oidc" }},
+		{"Created At", func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{"Field Boundary", func(e *AuditEvent) { e.Ip, e.UserAgent = "203.0.113.7curl", "/8.0" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newEvent()
+			tt.tamper(&event)
+
+			assert.NotEqual(t, hash, event.ComputeHash())
+		})
+	}
+
+	t.Run("Stable", func(t *testing.T) {
+		event := newEvent()
+		// the id is assigned by the database and is not hashed
+		event.Id = 42
+		event.CreatedAt = event.CreatedAt.In(time.FixedZone("UTC+2", 2*60*60))
+
+		assert.Equal(t, hash, event.ComputeHash())
+	})
+
+	t.Run("Nil Metadata", func(t *testing.T) {
+		event := newEvent()
+		event.Metadata = nil
+		empty := newEvent()
+		empty.Metadata = map[string]string{}
+
+		assert.Equal(t, empty.ComputeHash(), event.ComputeHash())
+	})
+}
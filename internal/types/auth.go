@@ -2,33 +2,233 @@ package types
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type UserIdKey string
+type TokenIdKey string
+type TokenExpiresAtKey string
+type EmailVerifiedKey string
+type ClientIpKey string
+type UserAgentKey string
+type SessionIdKey string
+type UserRolesKey string
+
+// roles a user can hold, stored in users.role
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Roles lists the roles an admin can grant
+var Roles = []string{RoleUser, RoleAdmin}
+
+var (
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+	ErrInvalidCode        = errors.New("invalid two-factor code")
+	ErrMfaEnabled         = errors.New("two-factor authentication is already enabled")
+	ErrMfaNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMfaNotEnrolled     = errors.New("two-factor authentication enrollment has not been started")
+	// returned for unknown emails and wrong passwords alike
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrLoginLocked        = errors.New("too many failed login attempts, try again later")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrAccountDisabled    = errors.New("account is disabled")
+	ErrInvalidRole        = errors.New("invalid role")
+)
+
+// LockedError reports how long a login stays locked out, it matches ErrLoginLocked
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// ReusedTokenError names the user whose session was ended because a rotated
+// refresh token was presented again, it matches ErrRefreshTokenReused
+type ReusedTokenError struct {
+	UserId    uuid.UUID
+	SessionId uuid.UUID
+}
+
+func (e *ReusedTokenError) Error() string {
+	return ErrRefreshTokenReused.Error()
+}
+
+func (e *ReusedTokenError) Unwrap() error {
+	return ErrRefreshTokenReused
+}
+
+// InvalidCodeError names the account a wrong second factor was given for, so
+// the failure counts towards its lockout, it matches ErrInvalidCode
+type InvalidCodeError struct {
+	Email string
+}
+
+func (e *InvalidCodeError) Error() string {
+	return ErrInvalidCode.Error()
+}
+
+func (e *InvalidCodeError) Unwrap() error {
+	return ErrInvalidCode
+}
 
 type User struct {
-	Id        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password,omitempty"`
-	Token     Token     `json:"token,omitempty"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	Id         uuid.UUID  `json:"id"`
+	Email      string     `json:"email"`
+	Password   string     `json:"password,omitempty"`
+	Token      Token      `json:"token,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	Role       string     `json:"role,omitempty"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// profile
+	DisplayName string    `json:"display_name,omitempty"`
+	Timezone    string    `json:"timezone,omitempty"`
+	Locale      string    `json:"locale,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
 }
 
 type Token struct {
 	AccessToken  string `json:"access_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
+	// set instead of the token pair when the login still needs a second factor
+	MfaToken string `json:"mfa_token,omitempty"`
 }
 
 type UserRequestBody struct {
 	Email    string `json:"email" example:"admin@gmail.com"`
-	Password string `json:"password" example:"admin"`
+	Password string `json:"password" example:"violet-Tractor-42"`
+}
+
+type RefreshRequestBody struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequestBody struct {
+	// optional, ends the session of this refresh token instead of the current one
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type LogoutAllRequestBody struct {
+	// optional, defaults to now
+	Before *time.Time `json:"before,omitempty"`
+}
+
+type VerifyRequestBody struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequestBody struct {
+	Email string `json:"email" example:"admin@gmail.com"`
+}
+
+type ForgotPasswordRequestBody struct {
+	Email string `json:"email" example:"admin@gmail.com"`
+}
+
+type ResetPasswordRequestBody struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type MagicLinkRequestBody struct {
+	Email string `json:"email" example:"admin@gmail.com"`
+}
+
+type MagicLinkConsumeRequestBody struct {
+	Token string `json:"token"`
+}
+
+type MfaLoginRequestBody struct {
+	MfaToken string `json:"mfa_token"`
+	// totp code or recovery code
+	Code string `json:"code" example:"123456"`
+}
+
+type TotpCodeRequestBody struct {
+	// totp code, or recovery code where accepted
+	Code string `json:"code" example:"123456"`
+}
+
+type TotpEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type Session struct {
+	Id         uuid.UUID `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	Ip         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// the session of the token making the request
+	Current bool `json:"current"`
 }
 
 type AuthServices interface {
 	Register(ctx context.Context, user UserRequestBody) (*User, error)
 	Login(ctx context.Context, user UserRequestBody) (*User, error)
+	Refresh(ctx context.Context, req RefreshRequestBody) (*Token, error)
+	Logout(ctx context.Context, req LogoutRequestBody) error
+	LogoutAll(ctx context.Context, req LogoutAllRequestBody) error
+	Verify(ctx context.Context, req VerifyRequestBody) error
+	ResendVerification(ctx context.Context, req ResendVerificationRequestBody) error
+	ForgotPassword(ctx context.Context, req ForgotPasswordRequestBody) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequestBody) error
+	RequestMagicLink(ctx context.Context, req MagicLinkRequestBody) error
+	ConsumeMagicLink(ctx context.Context, req MagicLinkConsumeRequestBody) (*User, error)
+	LoginMfa(ctx context.Context, req MfaLoginRequestBody) (*User, error)
+	EnrollTotp(ctx context.Context) (*TotpEnrollment, error)
+	ConfirmTotp(ctx context.Context, req TotpCodeRequestBody) (*RecoveryCodes, error)
+	DisableTotp(ctx context.Context, req TotpCodeRequestBody) error
+	RegenerateRecoveryCodes(ctx context.Context, req TotpCodeRequestBody) (*RecoveryCodes, error)
+	GetSessions(ctx context.Context) ([]Session, error)
+	DeleteSession(ctx context.Context, id uuid.UUID) error
+	GetMe(ctx context.Context) (*User, error)
+	UpdateMe(ctx context.Context, req ProfilePatchRequestBody) (*User, error)
+	ChangePassword(ctx context.Context, req ChangePasswordRequestBody) error
+	DeleteMe(ctx context.Context, req DeleteAccountRequestBody) error
+}
+
+type UsersQuery struct {
+	Limit  int
+	Offset int
+}
+
+type RoleRequestBody struct {
+	Role string `json:"role" example:"admin"`
+}
+
+type UnlockRequestBody struct {
+	// unlock the account with this email, the ip or both
+	Email string `json:"email,omitempty" example:"admin@gmail.com"`
+	Ip    string `json:"ip,omitempty" example:"127.0.0.1"`
+}
+
+type AdminServices interface {
+	Unlock(ctx context.Context, req UnlockRequestBody) error
+	GetUsers(ctx context.Context, query UsersQuery) ([]User, error)
+	DisableUser(ctx context.Context, id uuid.UUID) error
+	EnableUser(ctx context.Context, id uuid.UUID) error
+	ForcePasswordReset(ctx context.Context, id uuid.UUID) error
+	SetRole(ctx context.Context, id uuid.UUID, req RoleRequestBody) error
+	GetAuditEvents(ctx context.Context, query AuditQuery) ([]AuditEvent, error)
+	VerifyAuditLog(ctx context.Context) (*AuditVerification, error)
 }
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrInvalidChecklistTitle = errors.New("checklist item title must be between 1 and 255 characters")
+	ErrInvalidChecklistOrder = errors.New("item_ids must list every item of the checklist once")
+)
+
+// ChecklistItem is a step of a todo, items are listed by position
+type ChecklistItem struct {
+	Id        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistProgress counts the done items of a todo, 3 of 5 for instance
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Checklist is the checklist of a todo with the completion of the todo, which
+// follows the items when auto_complete is set
+type Checklist struct {
+	TodoId       uuid.UUID         `json:"todo_id"`
+	Completed    bool              `json:"completed"`
+	AutoComplete bool              `json:"auto_complete"`
+	Progress     ChecklistProgress `json:"progress"`
+	Items        []ChecklistItem   `json:"items"`
+}
+
+type ChecklistItemPostRequestBody struct {
+	Title string `json:"title" example:"Buy milk"`
+}
+
+type ChecklistItemPatchRequestBody struct {
+	Title *string `json:"title,omitempty" example:"Buy milk"`
+	Done  *bool   `json:"done,omitempty" example:"true"`
+}
+
+// ChecklistOrderRequestBody lists every item of a checklist in its new order
+type ChecklistOrderRequestBody struct {
+	ItemIds []uuid.UUID `json:"item_ids"`
+}
+
+// NormalizeChecklistTitle trims an item title and checks its length
+func NormalizeChecklistTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+
+	if title == "" || utf8.RuneCountInString(title) > 255 {
+		return "", ErrInvalidChecklistTitle
+	}
+
+	return title, nil
+}
+
+type ChecklistServices interface {
+	Get(ctx context.Context, todoId uuid.UUID) (*Checklist, error)
+	Add(ctx context.Context, todoId uuid.UUID, req ChecklistItemPostRequestBody) (*Checklist, error)
+	Update(ctx context.Context, todoId uuid.UUID, id uuid.UUID, req ChecklistItemPatchRequestBody) (*Checklist, error)
+	Reorder(ctx context.Context, todoId uuid.UUID, req ChecklistOrderRequestBody) (*Checklist, error)
+	Delete(ctx context.Context, todoId uuid.UUID, id uuid.UUID) (*Checklist, error)
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown identity provider")
+	ErrInvalidState     = errors.New("invalid or expired login state")
+	ErrEmailNotVerified = errors.New("email address is not verified by the identity provider")
+)
+
+// OidcStateTTL is how long a user may take at the identity provider
+const OidcStateTTL = 10 * time.Minute
+
+// ExternalIdentity is a user as asserted by an identity provider
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type OidcServices interface {
+	// AuthorizationUrl starts a login and returns where to send the user agent,
+	// and the binding the user agent has to present on the callback
+	AuthorizationUrl(ctx context.Context, provider string) (string, string, error)
+	Callback(ctx context.Context, provider string, code string, state string, binding string) (*User, error)
+}
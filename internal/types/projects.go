@@ -0,0 +1,91 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// DefaultProjectColor is given to projects created without a color
+const DefaultProjectColor = "#808080"
+
+// what happens to the todos of a deleted project
+const (
+	// the todos are kept without a project
+	ProjectDeleteInbox = "inbox"
+	// the todos are deleted with the project
+	ProjectDeleteCascade = "delete"
+)
+
+var (
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrInvalidProject       = errors.New("project not found in the workspace")
+	ErrInvalidProjectName   = errors.New("project name must be between 1 and 255 characters")
+	ErrInvalidProjectColor  = errors.New("project color must be a hex color such as #ff8800")
+	ErrInvalidProjectDelete = errors.New("todos must be inbox or delete")
+)
+
+// Project groups todos of a workspace, projects are listed by position
+type Project struct {
+	Id       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Color    string    `json:"color"`
+	Archived bool      `json:"archived"`
+	Position int       `json:"position"`
+	// number of todos in the project and how many of them are completed
+	Todos     int       `json:"todos"`
+	Completed int       `json:"completed"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ProjectPostRequestBody struct {
+	Name  string `json:"name" example:"Groceries"`
+	Color string `json:"color" example:"#ff8800"`
+	// the project goes after the others when missing
+	Position *int `json:"position,omitempty" example:"0"`
+}
+
+type ProjectPatchRequestBody struct {
+	Name     *string `json:"name,omitempty" example:"Groceries"`
+	Color    *string `json:"color,omitempty" example:"#ff8800"`
+	Archived *bool   `json:"archived,omitempty" example:"true"`
+	Position *int    `json:"position,omitempty" example:"1"`
+}
+
+// ProjectsQuery filters the project list, a nil field matches every project
+type ProjectsQuery struct {
+	Archived *bool
+}
+
+// NormalizeProjectName trims a project name and checks its length
+func NormalizeProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" || utf8.RuneCountInString(name) > 255 {
+		return "", ErrInvalidProjectName
+	}
+
+	return name, nil
+}
+
+func ValidateProjectColor(color string) error {
+	if !hexColorPattern.MatchString(color) {
+		return ErrInvalidProjectColor
+	}
+
+	return nil
+}
+
+type ProjectsServices interface {
+	Get(ctx context.Context, query ProjectsQuery) ([]Project, error)
+	Create(ctx context.Context, req ProjectPostRequestBody) (*Project, error)
+	GetById(ctx context.Context, id uuid.UUID) (*Project, error)
+	Update(ctx context.Context, id uuid.UUID, req ProjectPatchRequestBody) (*Project, error)
+	Delete(ctx context.Context, id uuid.UUID, mode string) error
+	GetTodos(ctx context.Context, id uuid.UUID, query TodosQuery) (*TodosPage, error)
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTagColor is given to tags created without a color
+const DefaultTagColor = "#808080"
+
+// colors of tags and projects are written as #rrggbb
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+var (
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrTagExists       = errors.New("a tag with this name already exists")
+	ErrInvalidTagName  = errors.New("tag name must be between 1 and 64 characters")
+	ErrInvalidTagColor = errors.New("tag color must be a hex color such as #ff8800")
+)
+
+// Tag labels todos of a workspace, names are unique regardless of case
+type Tag struct {
+	Id    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Color string    `json:"color"`
+	// number of todos with the tag
+	Todos     int       `json:"todos"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TagPostRequestBody struct {
+	Name  string `json:"name" example:"work"`
+	Color string `json:"color" example:"#ff8800"`
+}
+
+type TagPatchRequestBody struct {
+	Name  *string `json:"name,omitempty" example:"work"`
+	Color *string `json:"color,omitempty" example:"#ff8800"`
+}
+
+// NormalizeTagName trims a tag name and checks its length
+func NormalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" || utf8.RuneCountInString(name) > 64 {
+		return "", ErrInvalidTagName
+	}
+
+	return name, nil
+}
+
+// NormalizeTagNames normalizes every name and drops the ones repeated with another case
+func NormalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		name, err := NormalizeTagName(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+
+	return normalized, nil
+}
+
+func ValidateTagColor(color string) error {
+	if !hexColorPattern.MatchString(color) {
+		return ErrInvalidTagColor
+	}
+
+	return nil
+}
+
+type TagsServices interface {
+	Get(ctx context.Context) ([]Tag, error)
+	Create(ctx context.Context, req TagPostRequestBody) (*Tag, error)
+	GetById(ctx context.Context, id uuid.UUID) (*Tag, error)
+	Update(ctx context.Context, id uuid.UUID, req TagPatchRequestBody) (*Tag, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
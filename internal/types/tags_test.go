@@ -0,0 +1,65 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTagNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+		err      bool
+	}{
+		{"Trimmed", []string{" work ", "home"}, []string{"work", "home"}, false},
+		{"Repeated With Another Case", []string{"Work", "work", "WORK"}, []string{"Work"}, false},
+		{"Empty List", []string{}, []string{}, false},
+		{"Blank Name", []string{"work", "  "}, nil, true},
+		{"Too Long", []string{strings.Repeat("a", 65)}, nil, true},
+		{"Multibyte At Limit", []string{strings.Repeat("é", 64)}, []string{strings.Repeat("é", 64)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := NormalizeTagNames(tt.names)
+
+			if tt.err {
+				assert.ErrorIs(t, err, ErrInvalidTagName)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestValidateTagColor(t *testing.T) {
+	tests := []struct {
+		color string
+		valid bool
+	}{
+		{"#ff8800", true},
+		{"#FF8800", true},
+		{DefaultTagColor, true},
+		{"ff8800", false},
+		{"#f80", false},
+		{"#ff880g", false},
+		{"red", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			err := ValidateTagColor(tt.color)
+
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidTagColor)
+			}
+		})
+	}
+}
@@ -2,40 +2,197 @@ package types
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/odev-swe/todoapp/libs"
 )
 
+var (
+	ErrTodoNotFound  = errors.New("todo not found")
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidSearch = errors.New("search query has no words")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// TodosSortColumns lists the columns the todo list can be sorted by
+var TodosSortColumns = []string{"title", "completed", "due_date", "created_at", "updated_at"}
+
+// DefaultTodosSort lists the newest todos first
+var DefaultTodosSort = []TodosSort{{Column: "created_at", Desc: true}}
+
+// Todos fields with a patch tag, naming their column, can be changed by a merge patch
 type Todos struct {
-	Id          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	DueDate     time.Time `json:"due_date,omitempty" `
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id          uuid.UUID  `json:"id"`
+	Title       string     `json:"title" patch:"title"`
+	Description string     `json:"description" patch:"description"`
+	Completed   bool       `json:"completed" patch:"completed"`
+	DueDate     *time.Time `json:"due_date" patch:"due_date"`
+	// nil for the inbox
+	ProjectId *uuid.UUID `json:"project_id" patch:"project_id"`
+	// tag names, the tags are set apart from the columns of the todo
+	Tags []string `json:"tags" patch:"tags"`
+	// completed follows the checklist when set
+	AutoComplete bool              `json:"auto_complete" patch:"auto_complete"`
+	Progress     ChecklistProgress `json:"progress"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
 type TodosPostRequestBody struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	DueDate     time.Time `json:"due_date,omitempty" default:"2022-01-01T00:00:00Z"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	DueDate     *time.Time `json:"due_date,omitempty" default:"2022-01-01T00:00:00Z"`
+	// the inbox when missing
+	ProjectId *uuid.UUID `json:"project_id,omitempty"`
+	// tags missing from the workspace are created
+	Tags         []string `json:"tags,omitempty" example:"work,home"`
+	AutoComplete bool     `json:"auto_complete"`
 }
 
 type TodosPutRequestBody struct {
-	Id          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	DueDate     time.Time `json:"due_date,omitempty" default:"2022-01-01T00:00:00Z"`
+	// only read by the deprecated routes, the path id is used otherwise
+	Id          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	DueDate     *time.Time `json:"due_date,omitempty" default:"2022-01-01T00:00:00Z"`
+	// missing keeps the project, null moves the todo to the inbox
+	ProjectId OptionalUUID `json:"project_id" swaggertype:"string" format:"uuid"`
+	// missing tags are kept, an empty list clears them
+	Tags []string `json:"tags,omitempty" example:"work,home"`
+	// missing keeps the value
+	AutoComplete *bool `json:"auto_complete"`
+}
+
+// OptionalUUID tells a missing uuid field apart from null, Set is only true
+// when the field is in the body
+type OptionalUUID struct {
+	Set bool
+	Id  *uuid.UUID
+}
+
+func (o *OptionalUUID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+
+	return json.Unmarshal(data, &o.Id)
+}
+
+// TodosPatchRequestBody documents the merge patch of a todo, missing fields
+// keep their value, null clears due_date and tags and moves the todo to the inbox
+type TodosPatchRequestBody struct {
+	Title        *string    `json:"title"`
+	Description  *string    `json:"description"`
+	Completed    *bool      `json:"completed"`
+	DueDate      *time.Time `json:"due_date"`
+	ProjectId    *uuid.UUID `json:"project_id"`
+	Tags         []string   `json:"tags"`
+	AutoComplete *bool      `json:"auto_complete"`
 }
 
 type TodosDeleteRequestBody struct {
 	Id uuid.UUID `json:"id"`
 }
 
+// TodosQuery filters the todo list, nil and zero fields match every todo
+type TodosQuery struct {
+	Completed    *bool      `json:"completed,omitempty"`
+	DueBefore    *time.Time `json:"due_before,omitempty"`
+	DueAfter     *time.Time `json:"due_after,omitempty"`
+	CreatedAfter *time.Time `json:"created_after,omitempty"`
+	// incomplete todos whose due date has passed
+	Overdue bool `json:"overdue,omitempty"`
+	// case insensitive title substring
+	Q string `json:"q,omitempty"`
+	// todos of a project, or of no project with Inbox
+	ProjectId *uuid.UUID `json:"project_id,omitempty"`
+	Inbox     bool       `json:"inbox,omitempty"`
+	// tag names, todos need any of them or all of them with TagsMatchAll
+	Tags         []string    `json:"tags,omitempty"`
+	TagsMatchAll bool        `json:"tags_match_all,omitempty"`
+	Sort         []TodosSort `json:"sort,omitempty"`
+	Limit        int         `json:"limit"`
+	// the list pages with an opaque cursor, the search with an offset
+	Cursor string `json:"cursor,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	// count the todos matching the filters
+	Total bool `json:"total,omitempty"`
+}
+
+// TodosPage is a page of the todo list, the cursors are empty when there is
+// no page before or after it
+type TodosPage struct {
+	Todos      []Todos `json:"todos"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	PrevCursor string  `json:"prev_cursor,omitempty"`
+	Total      *int    `json:"total,omitempty"`
+}
+
+type TodosSort struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// ParseTodosSort reads a comma separated list of columns, a leading minus
+// sorts the column in descending order
+func ParseTodosSort(sort string) ([]TodosSort, error) {
+	var fields []TodosSort
+
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		if !slices.Contains(TodosSortColumns, name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSort, name)
+		}
+
+		for _, field := range fields {
+			if field.Column == name {
+				return nil, fmt.Errorf("%w: %q is repeated", ErrInvalidSort, name)
+			}
+		}
+
+		fields = append(fields, TodosSort{Column: name, Desc: desc})
+	}
+
+	return fields, nil
+}
+
+// TodoSearchResult is a todo matching a full-text search
+type TodoSearchResult struct {
+	Todos
+	Rank float32 `json:"rank"`
+	// the html escaped text with matched words wrapped in <mark>
+	TitleHighlight       string `json:"title_highlight"`
+	DescriptionHighlight string `json:"description_highlight"`
+}
+
+// TodosTsQuery turns a search into a tsquery matching every word as a prefix.
+// Only letters and digits are kept so the search can not use the tsquery syntax.
+func TodosTsQuery(search string) (string, error) {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) == 0 {
+		return "", ErrInvalidSearch
+	}
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & "), nil
+}
+
 type Pagination struct {
 	Limit  int `json:"limit"`
 	Offset int `json:"offset"`
@@ -43,7 +200,10 @@ type Pagination struct {
 
 type TodosServices interface {
 	Create(ctx context.Context, req TodosPostRequestBody) (*Todos, error)
-	Get(ctx context.Context) ([]Todos, error)
-	Update(ctx context.Context, req TodosPutRequestBody) (*Todos, error)
-	Delete(ctx context.Context, req TodosDeleteRequestBody) error
+	Get(ctx context.Context, query TodosQuery) (*TodosPage, error)
+	Search(ctx context.Context, query TodosQuery) ([]TodoSearchResult, error)
+	GetById(ctx context.Context, id uuid.UUID) (*Todos, error)
+	Update(ctx context.Context, id uuid.UUID, req TodosPutRequestBody) (*Todos, error)
+	Patch(ctx context.Context, id uuid.UUID, patch []libs.PatchField) (*Todos, error)
+	Delete(ctx context.Context, id uuid.UUID) error
 }
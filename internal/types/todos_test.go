@@ -0,0 +1,103 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTodosSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		expected []TodosSort
+		err      bool
+	}{
+		{"Single Column", "due_date", []TodosSort{{Column: "due_date"}}, false},
+		{"Descending", "due_date,-created_at", []TodosSort{{Column: "due_date"}, {Column: "created_at", Desc: true}}, false},
+		{"Spaces", " title , -completed ", []TodosSort{{Column: "title"}, {Column: "completed", Desc: true}}, false},
+		{"Unknown Column", "user_id", nil, true},
+		{"Injection", "created_at;DROP TABLE todos", nil, true},
+		{"Repeated Column", "title,-title", nil, true},
+		{"Empty Column", "title,", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseTodosSort(tt.sort)
+
+			if tt.err {
+				assert.ErrorIs(t, err, ErrInvalidSort)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, sort)
+		})
+	}
+}
+
+func TestTodosTsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		search   string
+		expected string
+		err      bool
+	}{
+		{"Single Word", "milk", "milk:*", false},
+		{"Every Word", "Buy  MILK", "buy:* & milk:*", false},
+		{"Unicode", "café crème", "café:* & crème:*", false},
+		{"Tsquery Syntax", "milk:* | !eggs & (bread)", "milk:* & eggs:* & bread:*", false},
+		{"Quotes", `it's "done"`, "it:* & s:* & done:*", false},
+		{"Empty", "", "", true},
+		{"No Words", " &|!():* ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tsQuery, err := TodosTsQuery(tt.search)
+
+			if tt.err {
+				assert.ErrorIs(t, err, ErrInvalidSearch)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tsQuery)
+		})
+	}
+}
+
+func TestTodosPutRequestBodyProjectId(t *testing.T) {
+	projectId := uuid.MustParse("4f9a1c3e-2b7d-4e8a-9c61-0d5e3f2a1b7c")
+
+	tests := []struct {
+		name     string
+		body     string
+		expected OptionalUUID
+		err      bool
+	}{
+		{"Missing", `{"title":"milk"}`, OptionalUUID{}, false},
+		{"Null", `{"project_id":null}`, OptionalUUID{Set: true}, false},
+		{"Project", `{"project_id":"4f9a1c3e-2b7d-4e8a-9c61-0d5e3f2a1b7c"}`, OptionalUUID{Set: true, Id: &projectId}, false},
+		{"Invalid", `{"project_id":"inbox"}`, OptionalUUID{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body TodosPutRequestBody
+
+			err := json.Unmarshal([]byte(tt.body), &body)
+
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, body.ProjectId)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PatPrefix marks personal access tokens so they can be told apart from jwts
+const PatPrefix = "todo_pat_"
+
+// PatScopes lists the scopes a personal access token can be granted,
+// a write scope implies the read scope of the same resource
+var PatScopes = []string{"todos:read", "todos:write"}
+
+type TokenScopesKey string
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrInvalidScope  = errors.New("invalid scope")
+)
+
+type PersonalAccessToken struct {
+	Id         uuid.UUID  `json:"id"`
+	UserId     uuid.UUID  `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// only returned once, on creation
+	Token string `json:"token,omitempty"`
+}
+
+type PersonalAccessTokenPostRequestBody struct {
+	Name string `json:"name" example:"ci"`
+	// empty grants every scope
+	Scopes    []string   `json:"scopes" example:"todos:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type PersonalAccessTokenPatchRequestBody struct {
+	Name   *string   `json:"name,omitempty" example:"ci"`
+	Scopes *[]string `json:"scopes,omitempty"`
+}
+
+// HasScope reports whether the granted scopes allow the required one
+func HasScope(granted []string, required string) bool {
+	if !slices.Contains(PatScopes, required) {
+		return false
+	}
+
+	// no explicit scopes means every scope
+	if len(granted) == 0 {
+		return true
+	}
+
+	if slices.Contains(granted, required) {
+		return true
+	}
+
+	resource, action, _ := strings.Cut(required, ":")
+
+	return action == "read" && slices.Contains(granted, resource+":write")
+}
+
+// ValidateScopes checks every scope is one of PatScopes
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !slices.Contains(PatScopes, scope) {
+			return ErrInvalidScope
+		}
+	}
+
+	return nil
+}
+
+type TokensServices interface {
+	Create(ctx context.Context, req PersonalAccessTokenPostRequestBody) (*PersonalAccessToken, error)
+	Get(ctx context.Context) ([]PersonalAccessToken, error)
+	GetById(ctx context.Context, id uuid.UUID) (*PersonalAccessToken, error)
+	Update(ctx context.Context, id uuid.UUID, req PersonalAccessTokenPatchRequestBody) (*PersonalAccessToken, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
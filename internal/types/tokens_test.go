@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required string
+		expected bool
+	}{
+		{"Unrestricted Token Reads", []string{}, "todos:read", true},
+		{"Unrestricted Token Writes", []string{}, "todos:write", true},
+		{"Read Only Token Reads", []string{"todos:read"}, "todos:read", true},
+		{"Read Only Token Writes", []string{"todos:read"}, "todos:write", false},
+		{"Write Implies Read", []string{"todos:write"}, "todos:read", true},
+		{"Unknown Resource", []string{}, "auth:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HasScope(tt.granted, tt.required))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceIdKey holds the workspace selected with the X-Workspace-ID header,
+// the personal workspace of the user is used when it is missing
+type WorkspaceIdKey string
+
+const WorkspaceHeader = "X-Workspace-ID"
+
+// workspace roles, from the most to the least privileged
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+	WorkspaceRoleViewer = "viewer"
+)
+
+var WorkspaceRoles = []string{WorkspaceRoleOwner, WorkspaceRoleAdmin, WorkspaceRoleMember, WorkspaceRoleViewer}
+
+var (
+	ErrWorkspaceNotFound      = errors.New("workspace not found")
+	ErrWorkspaceForbidden     = errors.New("workspace role does not allow this action")
+	ErrPersonalWorkspace      = errors.New("personal workspaces can not be shared or deleted")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrMemberExists           = errors.New("user is already a member")
+	ErrLastOwner              = errors.New("a workspace needs at least one owner")
+	ErrInvalidWorkspaceRole   = errors.New("invalid workspace role")
+	ErrInvalidWorkspaceName   = errors.New("workspace name must be between 1 and 255 characters")
+	ErrInvalidWorkspaceHeader = errors.New("invalid " + WorkspaceHeader + " header")
+)
+
+// WorkspaceRoleAtLeast reports whether role grants at least the privileges of min
+func WorkspaceRoleAtLeast(role string, min string) bool {
+	rank := slices.Index(WorkspaceRoles, role)
+
+	return rank != -1 && rank <= slices.Index(WorkspaceRoles, min)
+}
+
+type Workspace struct {
+	Id       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Personal bool      `json:"personal"`
+	// role of the current user
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WorkspaceMember struct {
+	UserId      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WorkspacePostRequestBody struct {
+	Name string `json:"name" example:"Team"`
+}
+
+type WorkspaceMemberPostRequestBody struct {
+	Email string `json:"email" example:"admin@gmail.com"`
+	Role  string `json:"role" example:"member"`
+}
+
+type WorkspaceMemberPatchRequestBody struct {
+	Role string `json:"role" example:"viewer"`
+}
+
+type WorkspacesServices interface {
+	Get(ctx context.Context) ([]Workspace, error)
+	Create(ctx context.Context, req WorkspacePostRequestBody) (*Workspace, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMembers(ctx context.Context, id uuid.UUID) ([]WorkspaceMember, error)
+	AddMember(ctx context.Context, id uuid.UUID, req WorkspaceMemberPostRequestBody) (*WorkspaceMember, error)
+	UpdateMember(ctx context.Context, id uuid.UUID, userId uuid.UUID, req WorkspaceMemberPatchRequestBody) (*WorkspaceMember, error)
+	RemoveMember(ctx context.Context, id uuid.UUID, userId uuid.UUID) error
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		min      string
+		expected bool
+	}{
+		{"Owner Manages Members", WorkspaceRoleOwner, WorkspaceRoleAdmin, true},
+		{"Admin Manages Members", WorkspaceRoleAdmin, WorkspaceRoleAdmin, true},
+		{"Member Can Not Manage Members", WorkspaceRoleMember, WorkspaceRoleAdmin, false},
+		{"Member Writes Todos", WorkspaceRoleMember, WorkspaceRoleMember, true},
+		{"Viewer Can Not Write Todos", WorkspaceRoleViewer, WorkspaceRoleMember, false},
+		{"Viewer Reads Todos", WorkspaceRoleViewer, WorkspaceRoleViewer, true},
+		{"Unknown Role", "guest", WorkspaceRoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WorkspaceRoleAtLeast(tt.role, tt.min))
+		})
+	}
+}
@@ -17,3 +17,7 @@ func BadRequest(w http.ResponseWriter, msg string) {
 func Unauthorized(w http.ResponseWriter, msg string) {
 	WriteJSON(w, false, http.StatusUnauthorized, msg, nil)
 }
+
+func Forbidden(w http.ResponseWriter, msg string) {
+	WriteJSON(w, false, http.StatusForbidden, msg, nil)
+}
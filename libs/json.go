@@ -11,9 +11,15 @@ type Response struct {
 	Status  bool   `json:"status"`
 	Code    int    `json:"code"`
 	Data    any    `json:"data,omitempty"`
+	Meta    *Meta  `json:"meta,omitempty"`
 }
 
 func WriteJSON(w http.ResponseWriter, status bool, code int, msg string, data any) error {
+	return WriteJSONWithMeta(w, status, code, msg, data, nil)
+}
+
+// WriteJSONWithMeta writes a response with pagination info
+func WriteJSONWithMeta(w http.ResponseWriter, status bool, code int, msg string, data any, meta *Meta) error {
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(code)
 
@@ -22,6 +28,7 @@ func WriteJSON(w http.ResponseWriter, status bool, code int, msg string, data an
 		Status:  status,
 		Code:    code,
 		Data:    data,
+		Meta:    meta,
 	}
 
 	return json.NewEncoder(w).Encode(res)
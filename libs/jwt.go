@@ -5,42 +5,74 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// CustomClaims are the claims of every token issued by the api, the user id
+// is the sub claim
 type CustomClaims struct {
 	jwt.RegisteredClaims
-	Data any `json:"data,omitempty"`
+	Type TokenType `json:"typ"`
+	// the login session the token belongs to
+	SessionId string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	// verification tokens are bound to the address they were sent to
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
 }
 
 type TokenType string
 
 const (
-	AccessToken  TokenType = "access"
-	RefreshToken TokenType = "refresh"
+	AccessToken      TokenType = "access"
+	RefreshToken     TokenType = "refresh"
+	VerifyEmailToken TokenType = "verify_email"
+	MfaPendingToken  TokenType = "mfa_pending"
 )
 
-func GenerateToken(data any, secret string, tokenType TokenType) (string, error) {
-	claims := &CustomClaims{
-		RegisteredClaims: jwt.RegisteredClaims{},
-		Data:             data,
-	}
-
+// TokenTTL returns how long a token of the given type stays valid
+func TokenTTL(tokenType TokenType) time.Duration {
 	switch tokenType {
 	case AccessToken:
-		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(15 * time.Second))
-	case RefreshToken:
-		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(24 * time.Hour))
+		return 15 * time.Second
+	case RefreshToken, VerifyEmailToken:
+		return 24 * time.Hour
+	case MfaPendingToken:
+		return 5 * time.Minute
+	}
+
+	return 0
+}
+
+// GenerateToken signs the claims, the issuer, audience and lifetime are set
+// from the key set and the token type. A random jti is used when none is given.
+func GenerateToken(claims CustomClaims, keys *KeySet) (string, error) {
+	now := time.Now()
+
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	claims.Issuer = keys.issuer
+	claims.Audience = jwt.ClaimStrings{keys.audience}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(TokenTTL(claims.Type)))
 
-	return token.SignedString([]byte(secret))
+	return keys.sign(&claims)
 }
 
-func ParseToken(tokenString string, secret string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+// ParseToken verifies the signature, issuer, audience and expiry of a token
+// and that it is of the expected type, so a refresh token can never be used
+// as an access token
+func ParseToken(tokenString string, keys *KeySet, tokenType TokenType) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, keys.keyfunc,
+		jwt.WithValidMethods(keys.algorithms()),
+		jwt.WithIssuer(keys.issuer),
+		jwt.WithAudience(keys.audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -52,5 +84,13 @@ func ParseToken(tokenString string, secret string) (*CustomClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if claims.Type != tokenType {
+		return nil, ErrWrongTokenType
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
 	return claims, nil
 }
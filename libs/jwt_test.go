@@ -0,0 +1,113 @@
+package libs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKeySet(issuer, audience string) *KeySet {
+	keys := NewHMACKeySet("secret")
+	keys.SetIdentity(issuer, audience)
+
+	return keys
+}
+
+func TestGenerateToken(t *testing.T) {
+	keys := testKeySet("todoapp", "todoapp-api")
+
+	token, err := GenerateToken(CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"},
+		Type:             AccessToken,
+		SessionId:        "session-1",
+		Roles:            []string{"admin"},
+		EmailVerified:    true,
+	}, keys)
+	require.NoError(t, err)
+
+	claims, err := ParseToken(token, keys, AccessToken)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "todoapp", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"todoapp-api"}, claims.Audience)
+	assert.Equal(t, "session-1", claims.SessionId)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.True(t, claims.EmailVerified)
+	assert.NotEmpty(t, claims.ID)
+	assert.WithinDuration(t, time.Now().Add(TokenTTL(AccessToken)), claims.ExpiresAt.Time, time.Second)
+}
+
+func TestParseTokenRejects(t *testing.T) {
+	keys := testKeySet("todoapp", "todoapp-api")
+
+	sign := func(keys *KeySet, claims CustomClaims) string {
+		token, err := GenerateToken(claims, keys)
+		require.NoError(t, err)
+		return token
+	}
+
+	subject := jwt.RegisteredClaims{Subject: "user-1"}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected TokenType
+	}{
+		{
+			name:     "refresh token as access token",
+			token:    sign(keys, CustomClaims{RegisteredClaims: subject, Type: RefreshToken}),
+			expected: AccessToken,
+		},
+		{
+			name:     "access token as refresh token",
+			token:    sign(keys, CustomClaims{RegisteredClaims: subject, Type: AccessToken}),
+			expected: RefreshToken,
+		},
+		{
+			name:     "verification token as access token",
+			token:    sign(keys, CustomClaims{RegisteredClaims: subject, Type: VerifyEmailToken}),
+			expected: AccessToken,
+		},
+		{
+			name:     "other issuer",
+			token:    sign(testKeySet("other", "todoapp-api"), CustomClaims{RegisteredClaims: subject, Type: AccessToken}),
+			expected: AccessToken,
+		},
+		{
+			name:     "other audience",
+			token:    sign(testKeySet("todoapp", "other"), CustomClaims{RegisteredClaims: subject, Type: AccessToken}),
+			expected: AccessToken,
+		},
+		{
+			name:     "no subject",
+			token:    sign(keys, CustomClaims{Type: AccessToken}),
+			expected: AccessToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseToken(tt.token, keys, tt.expected)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseTokenRejectsUntypedClaims(t *testing.T) {
+	keys := testKeySet("todoapp", "todoapp-api")
+
+	// tokens in the old format carried the user in a data claim
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"data": map[string]any{"id": "user-1"},
+		"exp":  time.Now().Add(time.Minute).Unix(),
+	})
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = ParseToken(signed, keys, AccessToken)
+	assert.Error(t, err)
+}
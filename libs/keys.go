@@ -0,0 +1,279 @@
+package libs
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    any
+	jwk    *JWK
+}
+
+// KeySet signs tokens with a single key and verifies them with any of its
+// active keys. Asymmetric keys are identified by their RFC 7638 thumbprint,
+// sent as the kid header, so keys can be rotated without downtime.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKid string
+	signingKey any
+	keys       map[string]verificationKey
+	// the iss and aud claims of issued tokens, required when parsing
+	issuer   string
+	audience string
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// rsa
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeySet signs and verifies HS256 tokens with a shared secret
+func NewHMACKeySet(secret string) *KeySet {
+	method := jwt.SigningMethodHS256
+
+	return &KeySet{
+		method:     method,
+		signingKey: []byte(secret),
+		keys: map[string]verificationKey{
+			"": {method: method, key: []byte(secret)},
+		},
+	}
+}
+
+// SetIdentity sets the issuer and audience tokens are issued for, tokens
+// naming another issuer or audience are rejected
+func (k *KeySet) SetIdentity(issuer, audience string) {
+	k.issuer = issuer
+	k.audience = audience
+}
+
+// LoadKeySet signs with the RSA (RS256) or Ed25519 (EdDSA) private key at
+// privateKeyPath. Tokens are also accepted when signed by the private key of
+// any public key in publicKeyPaths.
+func LoadKeySet(privateKeyPath string, publicKeyPaths []string) (*KeySet, error) {
+	data, err := os.ReadFile(privateKeyPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := parsePrivateKey(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", privateKeyPath, err)
+	}
+
+	set := &KeySet{keys: make(map[string]verificationKey)}
+
+	signing, err := set.add(private.Public())
+
+	if err != nil {
+		return nil, err
+	}
+
+	set.method = signing.method
+	set.signingKid = signing.jwk.Kid
+	set.signingKey = private
+
+	for _, path := range publicKeyPaths {
+		path = strings.TrimSpace(path)
+
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := parsePublicKey(data)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if _, err := set.add(public); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// add registers a public verification key under its thumbprint
+func (k *KeySet) add(public crypto.PublicKey) (*verificationKey, error) {
+	var key verificationKey
+
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		key = verificationKey{
+			method: jwt.SigningMethodRS256,
+			key:    public,
+			jwk: &JWK{
+				Kty: "RSA",
+				Alg: jwt.SigningMethodRS256.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			},
+		}
+		key.jwk.Kid = thumbprint(fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, key.jwk.E, key.jwk.N))
+	case ed25519.PublicKey:
+		key = verificationKey{
+			method: jwt.SigningMethodEdDSA,
+			key:    public,
+			jwk: &JWK{
+				Kty: "OKP",
+				Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			},
+		}
+		key.jwk.Kid = thumbprint(fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, key.jwk.X))
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+	}
+
+	key.jwk.Use = "sig"
+	k.keys[key.jwk.Kid] = key
+
+	return &key, nil
+}
+
+// JWKS returns the public keys of the set, empty for a shared secret
+func (k *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	for _, key := range k.keys {
+		if key.jwk != nil {
+			set.Keys = append(set.Keys, *key.jwk)
+		}
+	}
+
+	return set
+}
+
+func (k *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+
+	if k.signingKid != "" {
+		token.Header["kid"] = k.signingKid
+	}
+
+	return token.SignedString(k.signingKey)
+}
+
+// keyfunc picks the key named by the kid header and refuses any algorithm
+// other than the one that key was registered with
+func (k *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := k.keys[kid]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+
+	return key.key, nil
+}
+
+// algorithms lists every algorithm accepted by the set
+func (k *KeySet) algorithms() []string {
+	var algs []string
+
+	for _, key := range k.keys {
+		algs = append(algs, key.method.Alg())
+	}
+
+	return algs
+}
+
+func thumbprint(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		signer, ok := key.(crypto.Signer)
+
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+
+		return signer, nil
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+// parsePublicKey also accepts private keys and returns their public half
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	private, err := parsePrivateKey(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return private.Public(), nil
+}
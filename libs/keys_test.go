@@ -0,0 +1,167 @@
+package libs
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, key any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	return path
+}
+
+func writePublicKey(t *testing.T, key any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	return path
+}
+
+func TestKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  any
+		alg  string
+		kty  string
+	}{
+		{name: "rsa", key: rsaKey, alg: "RS256", kty: "RSA"},
+		{name: "ed25519", key: edKey, alg: "EdDSA", kty: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := LoadKeySet(writeKey(t, tt.key), nil)
+			require.NoError(t, err)
+
+			token, err := GenerateToken(CustomClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Type: AccessToken}, keys)
+			require.NoError(t, err)
+
+			claims, err := ParseToken(token, keys, AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, AccessToken, claims.Type)
+
+			jwks := keys.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, tt.alg, jwks.Keys[0].Alg)
+			assert.Equal(t, tt.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, "sig", jwks.Keys[0].Use)
+			assert.NotEmpty(t, jwks.Keys[0].Kid)
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldKeys, err := LoadKeySet(writeKey(t, oldKey), nil)
+	require.NoError(t, err)
+
+	token, err := GenerateToken(CustomClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Type: AccessToken}, oldKeys)
+	require.NoError(t, err)
+
+	// the new signing key still accepts tokens of the previous one
+	keys, err := LoadKeySet(writeKey(t, newKey), []string{writePublicKey(t, &oldKey.PublicKey)})
+	require.NoError(t, err)
+
+	_, err = ParseToken(token, keys, AccessToken)
+	assert.NoError(t, err)
+	assert.Len(t, keys.JWKS().Keys, 2)
+
+	// once dropped the previous key no longer verifies
+	keys, err = LoadKeySet(writeKey(t, newKey), nil)
+	require.NoError(t, err)
+
+	_, err = ParseToken(token, keys, AccessToken)
+	assert.Error(t, err)
+}
+
+func TestKeySetRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys, err := LoadKeySet(writeKey(t, rsaKey), nil)
+	require.NoError(t, err)
+
+	kid := keys.JWKS().Keys[0].Kid
+	publicDer, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{
+			name: "hmac signed with the public key",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, &CustomClaims{Type: AccessToken})
+				token.Header["kid"] = kid
+				signed, _ := token.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer}))
+				return signed
+			},
+		},
+		{
+			name: "none algorithm",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, &CustomClaims{Type: AccessToken})
+				token.Header["kid"] = kid
+				signed, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				return signed
+			},
+		},
+		{
+			name: "unknown kid",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, &CustomClaims{Type: AccessToken})
+				token.Header["kid"] = "unknown"
+				signed, _ := token.SignedString(rsaKey)
+				return signed
+			},
+		},
+		{
+			name: "shared secret",
+			token: func() string {
+				signed, _ := GenerateToken(CustomClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Type: AccessToken}, NewHMACKeySet("secret"))
+				return signed
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseToken(tt.token(), keys, AccessToken)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package libs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const MergePatchContentType = "application/merge-patch+json"
+
+var ErrInvalidMergePatch = errors.New("invalid merge patch")
+
+// PatchField is a column changed by a merge patch, a nil value clears it
+type PatchField struct {
+	Column string
+	Value  any
+}
+
+// ParseMergePatch reads a JSON merge patch (RFC 7396) of the struct model.
+// Fields are matched by their json name and only fields with a patch tag,
+// naming their column, can be changed. null clears pointer, slice and map
+// fields, any other value replaces the field as a whole. Fields are returned
+// in the order of the struct.
+func ParseMergePatch(data []byte, model any) ([]PatchField, error) {
+	var patch map[string]json.RawMessage
+
+	// a patch that is not an object would replace the whole resource
+	if err := json.Unmarshal(data, &patch); err != nil || patch == nil {
+		return nil, fmt.Errorf("%w: expected a JSON object", ErrInvalidMergePatch)
+	}
+
+	t := reflect.TypeOf(model)
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var fields []PatchField
+	known := make(map[string]bool, len(patch))
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		raw, ok := patch[name]
+
+		if !ok || name == "-" {
+			continue
+		}
+
+		known[name] = true
+		column := field.Tag.Get("patch")
+
+		if column == "" {
+			return nil, fmt.Errorf("%w: %s can not be changed", ErrInvalidMergePatch, name)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			switch field.Type.Kind() {
+			case reflect.Pointer, reflect.Slice, reflect.Map:
+				fields = append(fields, PatchField{Column: column})
+				continue
+			}
+
+			return nil, fmt.Errorf("%w: %s can not be null", ErrInvalidMergePatch, name)
+		}
+
+		value := reflect.New(field.Type)
+
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s", ErrInvalidMergePatch, name)
+		}
+
+		fields = append(fields, PatchField{Column: column, Value: value.Elem().Interface()})
+	}
+
+	for name := range patch {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidMergePatch, name)
+		}
+	}
+
+	return fields, nil
+}
+
+// jsonName is the name of a struct field in its JSON encoding
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
@@ -0,0 +1,53 @@
+package libs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type patchModel struct {
+	Id      string     `json:"id"`
+	Title   string     `json:"title" patch:"title"`
+	Tags    []string   `json:"tags,omitempty" patch:"tags"`
+	DueDate *time.Time `json:"due_date" patch:"due_date"`
+	Secret  string     `json:"-" patch:"secret"`
+}
+
+func TestParseMergePatch(t *testing.T) {
+	due := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		patch    string
+		expected []PatchField
+		err      bool
+	}{
+		{"Empty", `{}`, nil, false},
+		{"Struct Order", `{"due_date":"2026-10-18T09:00:00Z","title":"new"}`, []PatchField{{Column: "title", Value: "new"}, {Column: "due_date", Value: &due}}, false},
+		{"Null Clears Pointer", `{"due_date":null}`, []PatchField{{Column: "due_date"}}, false},
+		{"Null Clears Slice", `{"tags":null}`, []PatchField{{Column: "tags"}}, false},
+		{"Null Value", `{"title":null}`, nil, true},
+		{"Read Only", `{"id":"1"}`, nil, true},
+		{"Ignored Field", `{"-":"x"}`, nil, true},
+		{"Unknown", `{"title":"new","owner":"me"}`, nil, true},
+		{"Wrong Type", `{"title":1}`, nil, true},
+		{"Not An Object", `"title"`, nil, true},
+		{"Null Patch", `null`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseMergePatch([]byte(tt.patch), &patchModel{})
+
+			if tt.err {
+				assert.ErrorIs(t, err, ErrInvalidMergePatch)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, fields)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package libs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Meta describes the page returned in the data of a response
+type Meta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// only counted when asked for
+	Total *int `json:"total,omitempty"`
+}
+
+// PageLinks returns an RFC 8288 Link header linking the first, previous and
+// next pages of the request url, the pages differ in their cursor parameter
+func PageLinks(u *url.URL, meta *Meta) string {
+	rels := []struct {
+		rel    string
+		cursor string
+	}{
+		{"first", ""},
+		{"prev", meta.PrevCursor},
+		{"next", meta.NextCursor},
+	}
+
+	var links []string
+
+	for _, r := range rels {
+		// the first page has no cursor, missing pages are not linked
+		if r.cursor == "" && r.rel != "first" {
+			continue
+		}
+
+		query := u.Query()
+		query.Del("cursor")
+
+		if r.cursor != "" {
+			query.Set("cursor", r.cursor)
+		}
+
+		target := u.Path
+
+		if encoded := query.Encode(); encoded != "" {
+			target += "?" + encoded
+		}
+
+		links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", target, r.rel))
+	}
+
+	return strings.Join(links, ", ")
+}
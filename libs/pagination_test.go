@@ -0,0 +1,44 @@
+package libs
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		meta     Meta
+		expected string
+	}{
+		{
+			name:     "First Page",
+			url:      "/api/v1/todos?limit=10",
+			meta:     Meta{Limit: 10, NextCursor: "b"},
+			expected: `</api/v1/todos?limit=10>; rel="first", </api/v1/todos?cursor=b&limit=10>; rel="next"`,
+		},
+		{
+			name:     "Middle Page",
+			url:      "/api/v1/todos?cursor=b&completed=false",
+			meta:     Meta{Limit: 10, NextCursor: "c", PrevCursor: "a"},
+			expected: `</api/v1/todos?completed=false>; rel="first", </api/v1/todos?completed=false&cursor=a>; rel="prev", </api/v1/todos?completed=false&cursor=c>; rel="next"`,
+		},
+		{
+			name:     "Single Page",
+			url:      "/api/v1/todos",
+			meta:     Meta{Limit: 10},
+			expected: `</api/v1/todos>; rel="first"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+
+			assert.Equal(t, tt.expected, PageLinks(u, &tt.meta))
+		})
+	}
+}
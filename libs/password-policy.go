@@ -0,0 +1,98 @@
+package libs
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+//go:embed common-passwords.txt
+var commonPasswordsFile string
+
+// commonPasswords holds the bundled list, lowercased
+var commonPasswords = func() map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+
+	return passwords
+}()
+
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	// character classes required out of lowercase, uppercase, digits and symbols
+	MinClasses int
+	// reject passwords from the bundled common password list
+	RejectCommon bool
+}
+
+// Validate checks the password against the policy, email is the account it is
+// set for. The returned errors wrap ErrWeakPassword and explain what is wrong.
+func (p PasswordPolicy) Validate(password string, email string) error {
+	length := utf8.RuneCountInString(password)
+
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, p.MinLength)
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrWeakPassword, p.MaxLength)
+	}
+
+	if classes := passwordClasses(password); classes < p.MinClasses {
+		return fmt.Errorf("%w: must mix at least %d of lowercase, uppercase, digits and symbols", ErrWeakPassword, p.MinClasses)
+	}
+
+	lowered := strings.ToLower(password)
+
+	if email != "" {
+		local, _, _ := strings.Cut(strings.ToLower(email), "@")
+
+		if lowered == strings.ToLower(email) || (len(local) >= 4 && strings.Contains(lowered, local)) {
+			return fmt.Errorf("%w: must not contain the email address", ErrWeakPassword)
+		}
+	}
+
+	if p.RejectCommon {
+		if _, common := commonPasswords[lowered]; common {
+			return fmt.Errorf("%w: is too common", ErrWeakPassword)
+		}
+	}
+
+	return nil
+}
+
+func passwordClasses(password string) int {
+	var lower, upper, digit, symbol int
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
@@ -0,0 +1,87 @@
+package libs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// small parameters keep the tests fast
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.Contains(t, hash, "$argon2id$v=19$m=1024,t=1,p=1$")
+
+	other, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salts must differ")
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	stronger := testArgon2idParams
+	stronger.Iterations = 2
+
+	tests := []struct {
+		name        string
+		hasher      *Argon2idHasher
+		hash        string
+		password    string
+		ok          bool
+		needsRehash bool
+		err         error
+	}{
+		{"Matching Password", hasher, hash, "correct horse", true, false, nil},
+		{"Wrong Password", hasher, hash, "battery staple", false, false, nil},
+		{"Outdated Parameters", NewArgon2idHasher(stronger), hash, "correct horse", true, true, nil},
+		{"Bcrypt Hash", hasher, string(bcryptHash), "correct horse", true, true, nil},
+		{"Wrong Bcrypt Password", hasher, string(bcryptHash), "battery staple", false, false, nil},
+		{"Malformed Hash", hasher, "$argon2id$v=19$broken", "correct horse", false, false, ErrInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash, err := tt.hasher.Verify(tt.hash, tt.password)
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.needsRehash, needsRehash)
+		})
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MaxLength: 64, MinClasses: 2, RejectCommon: true}
+
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		valid    bool
+	}{
+		{"Strong Password", "violet-Tractor-42", "jane@example.com", true},
+		{"Too Short", "aB3$", "jane@example.com", false},
+		{"Too Long", "aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$", "jane@example.com", false},
+		{"Single Class", "violettractor", "jane@example.com", false},
+		{"Common Password", "Password1234", "jane@example.com", false},
+		{"Contains Email", "janedoe-2024!", "janedoe@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.email)
+
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrWeakPassword)
+			}
+		})
+	}
+}